@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azenv"
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/hooks"
+	"github.com/l2D/azswitch/internal/project"
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var (
+	flagAutoExport string
+	flagAutoHook   string
+	flagAutoQuiet  bool
+)
+
+var autoCmd = &cobra.Command{
+	Use:   "auto",
+	Short: "Apply the subscription pinned by a .azswitch file",
+	Long: `Look for a .azswitch file in the current directory or its parents and
+switch to the subscription it names.
+
+With --export, nothing is switched: azswitch prints shell commands that point
+the Azure CLI and SDKs at an isolated config directory for that subscription,
+for use with eval or direnv. When no .azswitch file applies, the commands undo
+a previous export.
+
+A .azswitch file is YAML:
+  subscription: Production   # ID or name
+  tenant: <tenant ID>        # optional
+  cloud: AzureCloud          # optional`,
+	Example: `  azswitch auto
+  eval "$(azswitch auto --export bash)"
+  eval "$(azswitch auto --hook zsh)"     # in ~/.zshrc`,
+	Args: cobra.NoArgs,
+	RunE: runAuto,
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the active account and check it against .azswitch",
+	Long: `Show the active account and, if a .azswitch file applies to the current
+directory, warn when the active subscription does not match it.
+
+Exits with status 1 on a mismatch.`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func init() {
+	autoCmd.Flags().StringVar(&flagAutoExport, "export", "", "Print environment exports for a shell (bash, zsh, sh, fish) instead of switching")
+	autoCmd.Flags().StringVar(&flagAutoHook, "hook", "", "Print a cd hook for a shell (bash, zsh, fish)")
+	autoCmd.Flags().BoolVarP(&flagAutoQuiet, "quiet", "q", false, "Only print when something changes")
+
+	rootCmd.AddCommand(autoCmd)
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runAuto(_ *cobra.Command, _ []string) error {
+	if flagAutoHook != "" {
+		return printShellHook(flagAutoHook)
+	}
+
+	file, err := findProjectFile()
+	if err != nil {
+		return err
+	}
+
+	if flagAutoExport != "" {
+		return exportProject(flagAutoExport, file)
+	}
+
+	if file == nil {
+		if !flagAutoQuiet {
+			fmt.Fprintln(os.Stderr, "No .azswitch file found")
+		}
+		return nil
+	}
+
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	account, err := client.GetCurrentAccount(ctx)
+	if err != nil {
+		return err
+	}
+	if err := file.CheckCloud(account); err != nil {
+		return err
+	}
+	if file.MatchesAccount(account) {
+		if !flagAutoQuiet {
+			fmt.Printf("Already using %s (%s)\n", account.Name, file.Path)
+		}
+		return nil
+	}
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	sub, err := file.Resolve(subs)
+	if err != nil {
+		return err
+	}
+
+	if isReadOnly(cfg) {
+		return fmt.Errorf("cannot switch to %s: %w", sub.Name, azure.ErrReadOnly)
+	}
+
+	return switchSubscription(ctx, client, hooks.NewRunner(cfg.Hooks), sub)
+}
+
+// findProjectFile returns the .azswitch file for the working directory, or nil if there is none.
+func findProjectFile() (*project.File, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := project.Find(wd)
+	if errors.Is(err, project.ErrNotFound) {
+		return nil, nil
+	}
+	return file, err
+}
+
+// exportProject prints shell commands exporting an isolated environment for
+// the project's subscription, or undoing a previous export.
+func exportProject(shell string, file *project.File) error {
+	sh, err := shellSyntaxFor(shell)
+	if err != nil {
+		return err
+	}
+
+	if file == nil {
+		base := os.Getenv(azenv.EnvBaseConfigDir)
+		if base == "" {
+			return nil
+		}
+		for _, name := range azenv.VariableNames() {
+			fmt.Println(sh.unset(name))
+		}
+		if def, err := azure.DefaultConfigDir(); err == nil && base != def {
+			fmt.Println(sh.export(azure.EnvConfigDir, base))
+		}
+		return nil
+	}
+
+	// Already exported for this project.
+	exported := azure.Subscription{
+		ID:       os.Getenv("ARM_SUBSCRIPTION_ID"),
+		Name:     os.Getenv(azenv.EnvSubscriptionName),
+		TenantID: os.Getenv("ARM_TENANT_ID"),
+	}
+	if os.Getenv(azenv.EnvBaseConfigDir) != "" && exported.ID != "" && file.Matches(&exported) {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	_, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	account, err := client.GetCurrentAccount(ctx)
+	if err != nil {
+		return err
+	}
+	if err := file.CheckCloud(account); err != nil {
+		return err
+	}
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	sub, err := file.Resolve(subs)
+	if err != nil {
+		return err
+	}
+
+	env, err := azenv.NewPersistent(sub)
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range env.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		fmt.Println(sh.export(name, value))
+	}
+	if !flagAutoQuiet {
+		fmt.Fprintf(os.Stderr, "azswitch: using %s (%s)\n", sub.Name, file.Path)
+	}
+
+	return nil
+}
+
+// shellSyntax formats environment changes for a shell.
+type shellSyntax struct {
+	export func(name, value string) string
+	unset  func(name string) string
+}
+
+// shellSyntaxFor returns the syntax for the named shell.
+func shellSyntaxFor(shell string) (shellSyntax, error) {
+	switch shell {
+	case "bash", "zsh", "sh":
+		return shellSyntax{
+			export: func(name, value string) string {
+				return fmt.Sprintf("export %s='%s'", name, strings.ReplaceAll(value, "'", `'\''`))
+			},
+			unset: func(name string) string {
+				return "unset " + name
+			},
+		}, nil
+	case "fish":
+		return shellSyntax{
+			export: func(name, value string) string {
+				value = strings.ReplaceAll(value, `\`, `\\`)
+				return fmt.Sprintf("set -gx %s '%s'", name, strings.ReplaceAll(value, "'", `\'`))
+			},
+			unset: func(name string) string {
+				return "set -e " + name
+			},
+		}, nil
+	default:
+		return shellSyntax{}, fmt.Errorf("unsupported shell %q (supported: bash, zsh, sh, fish)", shell)
+	}
+}
+
+// printShellHook prints a snippet that runs 'azswitch auto --export' on every directory change.
+func printShellHook(shell string) error {
+	switch shell {
+	case "bash":
+		fmt.Print(`_azswitch_hook() {
+  if [ "$PWD" != "$_AZSWITCH_LAST_PWD" ]; then
+    _AZSWITCH_LAST_PWD="$PWD"
+    eval "$(azswitch auto --export bash)"
+  fi
+}
+PROMPT_COMMAND="_azswitch_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`)
+	case "zsh":
+		fmt.Print(`_azswitch_hook() {
+  eval "$(azswitch auto --export zsh)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _azswitch_hook
+_azswitch_hook
+`)
+	case "fish":
+		fmt.Print(`function _azswitch_hook --on-variable PWD
+    azswitch auto --export fish | source
+end
+_azswitch_hook
+`)
+	default:
+		return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", shell)
+	}
+	return nil
+}
+
+func runStatus(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	_, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := showCurrent(ctx, client); err != nil {
+		return err
+	}
+
+	file, err := findProjectFile()
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return nil
+	}
+
+	account, err := client.GetCurrentAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Project file: %s\n", file.Path)
+	if file.MatchesAccount(account) {
+		fmt.Printf("  %s Active subscription matches\n", tui.SuccessStyle.Render("✓"))
+		return nil
+	}
+
+	want := file.Subscription
+	if want == "" {
+		want = "a subscription in tenant " + file.Tenant
+	}
+	fmt.Fprintf(os.Stderr, "  %s Active subscription %s does not match %s. Run: azswitch auto\n", tui.WarningStyle.Render("⚠"), account.Name, want)
+	return exitCodeError{code: 1}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show, edit and validate the config file",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigShow,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $VISUAL or $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigEdit,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Check the config file for errors",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runConfigValidate,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file location",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		path, err := configPath()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd, configEditCmd, configValidateCmd, configPathCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(_ *cobra.Command, _ []string) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("# %s does not exist; showing defaults\n", path)
+	} else {
+		fmt.Printf("# %s\n", path)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func runConfigEdit(_ *cobra.Command, _ []string) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(config.Template), 0o600); err != nil {
+			return fmt.Errorf("failed to create config: %w", err)
+		}
+	}
+
+	args := append(editorCommand(), path)
+	editor := exec.Command(args[0], args[1:]...)
+	editor.Stdin = os.Stdin
+	editor.Stdout = os.Stdout
+	editor.Stderr = os.Stderr
+	if err := editor.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	if _, err := config.Load(path); err != nil {
+		fmt.Fprintln(os.Stderr, "The config file has errors:")
+		return err
+	}
+	return nil
+}
+
+// editorCommand returns the user's editor and its arguments, such as
+// "code --wait".
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(env)); len(editor) > 0 {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func runConfigValidate(_ *cobra.Command, args []string) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot validate %s: %w", path, err)
+	}
+
+	if _, err := config.Load(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is valid\n", path)
+	return nil
+}
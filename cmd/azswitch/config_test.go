@@ -0,0 +1,24 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditorCommand_SplitsArguments(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+
+	if got := editorCommand(); !slices.Equal(got, []string{"code", "--wait"}) {
+		t.Errorf("expected the editor and its flag, got %q", got)
+	}
+}
+
+func TestEditorCommand_PrefersVisual(t *testing.T) {
+	t.Setenv("VISUAL", "nvim")
+	t.Setenv("EDITOR", "nano")
+
+	if got := editorCommand(); !slices.Equal(got, []string{"nvim"}) {
+		t.Errorf("expected VISUAL, got %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azenv"
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <subscription> -- <command> [args...]",
+	Short: "Run a command against a subscription without switching",
+	Long: `Run a single command against a subscription without changing the default
+az account. The command runs with an isolated copy of the Azure CLI config
+directory and AZURE_*/ARM_* environment variables set for the subscription.
+
+azswitch exits with the command's exit code.`,
+	Example: `  azswitch exec "Production" -- az group list
+  azswitch exec 00000000-0000-0000-0000-000000000000 -- terraform plan`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runExec,
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() != 1 {
+		return fmt.Errorf("expected exactly one subscription before --, got %d arguments", max(cmd.ArgsLenAtDash(), len(args)-1))
+	}
+
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	sub, err := resolveSubscription(ctx, client, cfg, subs, args[0])
+	if err != nil {
+		return err
+	}
+
+	return runIsolated(ctx, sub, args[1:])
+}
+
+// runIsolated runs argv with the subscription's isolated Azure environment,
+// connected to the terminal, and returns an exitCodeError if it fails.
+func runIsolated(ctx context.Context, sub azure.Subscription, argv []string) error {
+	env, err := azenv.New(sub)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	// Let the child handle Ctrl+C so the isolated directory is still cleaned up.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	child := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	child.Env = append(os.Environ(), env.Environ()...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitCodeError{code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("failed to run %s: %w", argv[0], err)
+	}
+
+	return nil
+}
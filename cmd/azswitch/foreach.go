@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/batch"
+	"github.com/l2D/azswitch/internal/filter"
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var (
+	flagForeachFilter   string
+	flagForeachParallel int
+	flagForeachReport   string
+)
+
+var foreachCmd = &cobra.Command{
+	Use:   "foreach [--filter <expr>] -- <command> [args...]",
+	Short: "Run a command in every matching subscription",
+	Long: `Run a command once per subscription, each with its own isolated Azure CLI
+environment (see 'azswitch exec'). Output is streamed with a [subscription]
+prefix on every line, followed by a summary.
+
+Filter expressions are comma-separated terms that must all match:
+  field=value   equal          field!=value  not equal
+  field~value   contains       field!~value  does not contain
+Fields: name, id, tenant, state, cloud, user. A bare word matches names.
+
+azswitch exits non-zero if the command failed in any subscription.`,
+	Example: `  azswitch foreach --filter "state=Enabled,name~prod" -- az group list -o table
+  azswitch foreach --parallel 4 --report audit.json -- az policy state summarize`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runForeach,
+}
+
+func init() {
+	foreachCmd.Flags().StringVarP(&flagForeachFilter, "filter", "f", "", "Only run in subscriptions matching this expression")
+	foreachCmd.Flags().IntVarP(&flagForeachParallel, "parallel", "p", 1, "Number of subscriptions to run at once")
+	foreachCmd.Flags().StringVar(&flagForeachReport, "report", "", "Write a JSON report to this file")
+
+	rootCmd.AddCommand(foreachCmd)
+}
+
+func runForeach(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() != 0 {
+		return fmt.Errorf("the command must follow --")
+	}
+
+	f, err := filter.Parse(flagForeachFilter)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	// Filters on tags need them even when they are not enabled.
+	if f.UsesTags() {
+		cfg.Tags.Enabled = true
+	}
+	enrichTags(ctx, client, cfg, subs)
+
+	subs = f.Apply(subs)
+	if len(subs) == 0 {
+		return fmt.Errorf("no subscriptions match filter %q", flagForeachFilter)
+	}
+
+	runner := &batch.Runner{
+		Parallel: flagForeachParallel,
+		Output:   os.Stdout,
+	}
+	report := runner.Run(ctx, subs, args)
+
+	printReport(&report)
+
+	if flagForeachReport != "" {
+		if err := writeReport(flagForeachReport, &report); err != nil {
+			return err
+		}
+	}
+
+	if report.Failed > 0 {
+		return exitCodeError{code: 1}
+	}
+	return nil
+}
+
+// printReport prints the per-subscription outcome and totals.
+func printReport(report *batch.Report) {
+	fmt.Println()
+	fmt.Printf("Summary: %d succeeded, %d failed\n", report.Succeeded, report.Failed)
+	for i := range report.Results {
+		r := &report.Results[i]
+		switch {
+		case r.Error != "":
+			fmt.Printf("  %s %s: %s\n", tui.ErrorStyle.Render("✗"), r.SubscriptionName, r.Error)
+		case r.ExitCode != 0:
+			fmt.Printf("  %s %s (exit %d, %.1fs)\n", tui.ErrorStyle.Render("✗"), r.SubscriptionName, r.ExitCode, r.DurationSeconds)
+		default:
+			fmt.Printf("  %s %s (%.1fs)\n", tui.SuccessStyle.Render("✓"), r.SubscriptionName, r.DurationSeconds)
+		}
+	}
+}
+
+// writeReport writes the report as indented JSON.
+func writeReport(path string, report *batch.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
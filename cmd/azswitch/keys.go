@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Print the effective TUI key bindings",
+	Long: `Print the key bindings of every TUI action, with the overrides from the
+config file applied. Override them under 'keys:' in the config file.`,
+	Args: cobra.NoArgs,
+	RunE: runKeys,
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+}
+
+func runKeys(_ *cobra.Command, _ []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	keys := tui.KeyMapFromConfig(cfg)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tKEYS\tDESCRIPTION")
+	for _, action := range config.KeyActions {
+		b, ok := keys.Lookup(action)
+		if !ok {
+			continue
+		}
+		note := ""
+		if _, overridden := cfg.Keys[action]; overridden {
+			note = " (custom)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s%s\n", action, strings.Join(tui.KeyNames(b), ", "), b.Help().Desc, note)
+	}
+	fmt.Fprintf(w, "\t%s\talways quits\n", config.ReservedKey)
+
+	return w.Flush()
+}
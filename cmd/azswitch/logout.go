@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+var (
+	flagLogoutTenant string
+	flagLogoutUser   string
+	flagLogoutAll    bool
+	flagLogoutYes    bool
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Sign out of the Azure CLI",
+	Long: `Sign out of the Azure CLI.
+
+Without flags, the current user is signed out. --user signs out another
+signed-in user. --tenant removes a tenant's subscriptions from the profile,
+keeping the user signed in to other tenants. --all signs every user out.
+
+Asks before signing out unless --yes is given.`,
+	Example: `  azswitch logout
+  azswitch logout --user admin@contoso.com
+  azswitch logout --tenant xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+  azswitch logout --all --yes`,
+	Args: cobra.NoArgs,
+	RunE: runLogout,
+}
+
+func init() {
+	logoutCmd.Flags().StringVar(&flagLogoutTenant, "tenant", "", "Remove the subscriptions of this tenant ID")
+	logoutCmd.Flags().StringVar(&flagLogoutUser, "user", "", "Sign out this user instead of the current one")
+	logoutCmd.Flags().BoolVar(&flagLogoutAll, "all", false, "Sign out every user")
+	logoutCmd.Flags().BoolVarP(&flagLogoutYes, "yes", "y", false, "Do not ask for confirmation")
+	logoutCmd.MarkFlagsMutuallyExclusive("tenant", "user", "all")
+
+	rootCmd.AddCommand(logoutCmd)
+}
+
+func runLogout(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+	if isReadOnly(cfg) {
+		return fmt.Errorf("cannot sign out: %w", azure.ErrReadOnly)
+	}
+
+	switch {
+	case flagLogoutAll:
+		if !flagLogoutYes && !confirm("Sign out every user and clear all subscriptions?", false) {
+			return notConfirmed()
+		}
+		if err := client.LogoutAll(ctx); err != nil {
+			return fmt.Errorf("failed to sign out: %w", err)
+		}
+		fmt.Println("Signed out of every account")
+		return nil
+
+	case flagLogoutTenant != "":
+		return logoutTenant(ctx, client, flagLogoutTenant)
+	}
+
+	user := flagLogoutUser
+	if user == "" {
+		account, err := client.GetCurrentAccount(ctx)
+		if err != nil {
+			return err
+		}
+		user = account.User.Name
+	}
+
+	if !flagLogoutYes && !confirm(fmt.Sprintf("Sign out %s?", user), false) {
+		return notConfirmed()
+	}
+	if err := client.Logout(ctx, user); err != nil {
+		return fmt.Errorf("failed to sign out: %w", err)
+	}
+	fmt.Printf("Signed out %s\n", user)
+	return nil
+}
+
+// logoutTenant removes the subscriptions of a tenant from the profile.
+func logoutTenant(ctx context.Context, client azure.Client, tenantID string) error {
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var ids, names []string
+	for i := range subs {
+		if strings.EqualFold(subs[i].TenantID, tenantID) {
+			ids = append(ids, subs[i].ID)
+			names = append(names, subs[i].Name)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("no subscriptions of tenant %s in the profile", tenantID)
+	}
+
+	fmt.Printf("Subscriptions of tenant %s:\n", tenantID)
+	for _, name := range names {
+		fmt.Printf("  %s\n", name)
+	}
+	if !flagLogoutYes && !confirm(fmt.Sprintf("Remove %d subscriptions from the profile?", len(ids)), false) {
+		return notConfirmed()
+	}
+
+	if err := client.RemoveSubscriptions(ctx, ids); err != nil {
+		return fmt.Errorf("failed to remove subscriptions: %w", err)
+	}
+	fmt.Printf("Removed %d subscriptions of tenant %s\n", len(ids), tenantID)
+	return nil
+}
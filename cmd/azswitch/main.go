@@ -3,13 +3,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
 	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/history"
+	"github.com/l2D/azswitch/internal/hooks"
+	"github.com/l2D/azswitch/internal/listing"
+	"github.com/l2D/azswitch/internal/resolve"
+	"github.com/l2D/azswitch/internal/tags"
+	"github.com/l2D/azswitch/internal/tokens"
 	"github.com/l2D/azswitch/internal/tui"
 	"github.com/l2D/azswitch/internal/version"
 )
@@ -20,10 +30,30 @@ var (
 	flagCurrent      bool
 	flagSubscription string
 	flagTenant       string
+	flagConfig       string
+	flagARM          bool
+	flagReadOnly     bool
+	flagInline       bool
+	flagHeight       int
+
+	flagAllowNoSubscriptions bool
 )
 
+// exitCodeError makes azswitch exit with a child process's exit code.
+type exitCodeError struct {
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -41,48 +71,133 @@ Run without flags to enter interactive mode.`,
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&flagConfig, "config", "", "Path to config file (default $XDG_CONFIG_HOME/azswitch/config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&flagARM, "arm", false, "List subscriptions and tenants from Azure Resource Manager (azure.backend: arm)")
+	rootCmd.PersistentFlags().BoolVar(&flagReadOnly, "read-only", false, "Browse subscriptions and tenants without switching")
 	rootCmd.Flags().BoolVarP(&flagList, "list", "l", false, "List all subscriptions")
 	rootCmd.Flags().BoolVarP(&flagCurrent, "current", "c", false, "Show current account")
-	rootCmd.Flags().StringVarP(&flagSubscription, "subscription", "s", "", "Switch to subscription by ID or name")
+	rootCmd.Flags().StringVarP(&flagSubscription, "subscription", "s", "", "Switch to subscription by ID, ID prefix, name, tenant domain or alias")
 	rootCmd.Flags().StringVarP(&flagTenant, "tenant", "t", "", "Switch to tenant by ID")
+	rootCmd.Flags().BoolVar(&flagAllowNoSubscriptions, "allow-no-subscriptions", false, "Log in to --tenant at tenant level, for tenants without subscriptions")
+	rootCmd.Flags().BoolVar(&flagInline, "inline", false, "Show a compact picker below the prompt instead of a full-screen TUI")
+	rootCmd.Flags().IntVar(&flagHeight, "height", 0, "Lines used by the inline picker (implies --inline)")
 
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
 }
 
-func run(_ *cobra.Command, _ []string) error {
+// configPath returns the config file location from --config, AZSWITCH_CONFIG or the default.
+func configPath() (string, error) {
+	if flagConfig != "" {
+		return flagConfig, nil
+	}
+	return config.DefaultPath()
+}
+
+// loadConfig loads and validates the config file, applies its theme and
+// the list order flags.
+func loadConfig() (*config.Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTheme(cfg); err != nil {
+		return nil, err
+	}
+	if err := applyListOrder(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// setup loads the config and returns a client of the configured backend,
+// once the Azure CLI is installed and logged in.
+func setup(ctx context.Context) (*config.Config, azure.Client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if flagARM {
+		cfg.Azure.Backend = config.BackendARM
+	}
+
 	client := azure.NewCLIClient()
-	ctx := context.Background()
 
 	// Check if Azure CLI is installed
 	if err := client.CheckCLI(ctx); err != nil {
 		//nolint:staticcheck // ST1005: Azure CLI is a proper noun
-		return fmt.Errorf("Azure CLI is not installed. Install from: https://docs.microsoft.com/en-us/cli/azure/install-azure-cli")
+		return nil, nil, fmt.Errorf("Azure CLI is not installed. Install from: https://docs.microsoft.com/en-us/cli/azure/install-azure-cli")
 	}
 
 	// Check if logged in
 	if err := client.CheckLogin(ctx); err != nil {
-		return fmt.Errorf("not logged in to Azure CLI. Run: az login")
+		return nil, nil, fmt.Errorf("not logged in to Azure CLI. Run: az login")
 	}
 
+	if cfg.Azure.Backend == config.BackendARM {
+		return cfg, azure.NewARMClient(client), nil
+	}
+	return cfg, client, nil
+}
+
+// isReadOnly reports whether --read-only or behavior.read_only is set.
+func isReadOnly(cfg *config.Config) bool {
+	return flagReadOnly || cfg.Behavior.ReadOnly
+}
+
+func run(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	cfg, cliClient, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	var client azure.Client = cliClient
+	readOnly := isReadOnly(cfg)
+	if readOnly {
+		client = azure.NewReadOnlyClient(client)
+	}
+	runner := hooks.NewRunner(cfg.Hooks)
+
 	// Handle non-interactive flags
 	if flagCurrent {
 		return showCurrent(ctx, client)
 	}
 
 	if flagList {
-		return listSubscriptions(ctx, client)
+		return listSubscriptions(ctx, client, cfg)
+	}
+
+	if readOnly && (flagSubscription != "" || flagTenant != "") {
+		return fmt.Errorf("cannot switch: %w", azure.ErrReadOnly)
 	}
 
 	if flagSubscription != "" {
-		return switchSubscription(ctx, client, flagSubscription)
+		subs, err := client.ListSubscriptions(ctx)
+		if err != nil {
+			return err
+		}
+		sub, err := resolveSubscription(ctx, client, cfg, subs, flagSubscription)
+		if err != nil {
+			return err
+		}
+		return switchSubscription(ctx, client, runner, sub)
 	}
 
 	if flagTenant != "" {
-		return switchTenant(ctx, client, flagTenant)
+		return switchTenant(ctx, client, runner, flagTenant)
 	}
 
 	// Interactive mode
-	return runInteractive(client)
+	return runInteractive(client, cfg, runner, readOnly)
 }
 
 func showCurrent(ctx context.Context, client azure.Client) error {
@@ -98,60 +213,240 @@ func showCurrent(ctx context.Context, client azure.Client) error {
 	fmt.Printf("  ID:           %s\n", account.ID)
 	fmt.Printf("  State:        %s\n", account.State)
 
+	now := time.Now()
+	fmt.Printf("  Token:        %s\n", styleToken(tokens.Check(ctx, client, account.TenantID, now), now))
+
 	return nil
 }
 
-func listSubscriptions(ctx context.Context, client azure.Client) error {
+// listSubscriptions prints the subscriptions in the configured order, under
+// a heading per group when grouped.
+func listSubscriptions(ctx context.Context, client azure.Client, cfg *config.Config) error {
 	subs, err := client.ListSubscriptions(ctx)
 	if err != nil {
 		return err
 	}
+	enrichTags(ctx, client, cfg, subs)
+
+	// Users are only told apart once more than one is signed in.
+	showUsers := len(azure.Users(subs)) > 1
 
 	fmt.Println("Available Subscriptions:")
-	for i := range subs {
-		sub := &subs[i]
-		indicator := "  "
-		if sub.IsDefault {
-			indicator = "* "
+	for _, group := range orderSubscriptions(subs, cfg.List) {
+		if group.Title != "" {
+			fmt.Printf("\n%s (%d)\n", tui.TitleStyle.UnsetMarginBottom().Render(group.Title), len(group.Subscriptions))
+		}
+		for i := range group.Subscriptions {
+			sub := &group.Subscriptions[i]
+			indicator, name := "  ", sub.Name
+			if sub.IsDefault {
+				indicator, name = "* ", tui.CurrentStyle.Render(sub.Name)
+			}
+			fmt.Printf("%s%s\n", indicator, name)
+			fmt.Printf("    ID:    %s\n", sub.ID)
+			fmt.Printf("    State: %s\n", sub.State)
+			if showUsers {
+				fmt.Printf("    User:  %s\n", sub.User.Name)
+			}
+			if pairs := tags.Format(sub.Tags, cfg.Tags.Show); len(pairs) > 0 {
+				fmt.Printf("    Tags:  %s\n", strings.Join(pairs, " "))
+			}
 		}
-		fmt.Printf("%s%s\n", indicator, sub.Name)
-		fmt.Printf("    ID:    %s\n", sub.ID)
-		fmt.Printf("    State: %s\n", sub.State)
 	}
 
 	return nil
 }
 
-func switchSubscription(ctx context.Context, client azure.Client, subscription string) error {
-	fmt.Printf("Switching to subscription: %s\n", subscription)
+func switchSubscription(ctx context.Context, client azure.Client, runner *hooks.Runner, sub azure.Subscription) error {
+	fmt.Printf("Switching to subscription: %s\n", sub.Name)
 
-	if err := client.SetSubscription(ctx, subscription); err != nil {
-		return fmt.Errorf("failed to switch subscription: %w", err)
+	ev := hooks.Event{
+		Kind:                hooks.KindSubscription,
+		NewSubscriptionID:   sub.ID,
+		NewSubscriptionName: sub.Name,
+		NewTenantID:         sub.TenantID,
 	}
 
-	fmt.Println("Successfully switched subscription")
-	return showCurrent(ctx, client)
+	return runSwitch(ctx, client, runner, ev, func() error {
+		if err := client.SetSubscription(ctx, sub.ID); err != nil {
+			return fmt.Errorf("failed to switch subscription: %w", err)
+		}
+		recordSwitch(sub.ID)
+		fmt.Println("Successfully switched subscription")
+		return nil
+	})
 }
 
-func switchTenant(ctx context.Context, client azure.Client, tenant string) error {
+func switchTenant(ctx context.Context, client azure.Client, runner *hooks.Runner, tenant string) error {
 	fmt.Printf("Switching to tenant: %s\n", tenant)
-	fmt.Println("This will open a browser for authentication...")
 
-	if err := client.LoginToTenant(ctx, tenant); err != nil {
-		return fmt.Errorf("failed to switch tenant: %w", err)
+	ev := hooks.Event{
+		Kind:        hooks.KindTenant,
+		NewTenantID: tenant,
+	}
+
+	return runSwitch(ctx, client, runner, ev, func() error {
+		// A tenant with a known subscription and a valid token needs no
+		// login. Without the list, the tenant is logged in to.
+		subs, _ := client.ListSubscriptions(ctx)
+		sub, err := azure.SwitchToTenant(ctx, client, tenant, recentFirst(subs))
+		if err == nil {
+			recordSwitch(sub.ID)
+			fmt.Printf("Switched to %s without a new login\n", sub.Name)
+			return nil
+		}
+		if !azure.NeedsLogin(err) {
+			return fmt.Errorf("failed to switch tenant: %w", err)
+		}
+
+		opts := azure.LoginOptions{
+			AllowNoSubscriptions: flagAllowNoSubscriptions || errors.Is(err, azure.ErrNoSubscriptions),
+		}
+		fmt.Println("This will open a browser for authentication...")
+		if err := client.LoginToTenant(ctx, tenant, opts); err != nil {
+			return fmt.Errorf("failed to switch tenant: %w", err)
+		}
+		fmt.Println("Successfully switched tenant")
+		return nil
+	})
+}
+
+// runSwitch wraps a switch in the pre- and post-switch hooks and prints their output.
+func runSwitch(ctx context.Context, client azure.Client, runner *hooks.Runner, ev hooks.Event, doSwitch func() error) error {
+	if account, err := client.GetCurrentAccount(ctx); err == nil {
+		ev = ev.WithOld(account)
+	}
+
+	output, err := runner.Pre(ctx, ev)
+	printHookOutput(output)
+	if err != nil {
+		return err
+	}
+
+	if err := doSwitch(); err != nil {
+		return err
+	}
+
+	if account, err := client.GetCurrentAccount(ctx); err == nil {
+		ev = ev.WithNew(account)
+	}
+
+	output, err = runner.Post(ctx, ev)
+	printHookOutput(output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: post-switch hook failed: %v\n", err)
 	}
 
-	fmt.Println("Successfully switched tenant")
 	return showCurrent(ctx, client)
 }
 
-func runInteractive(client azure.Client) error {
-	model := tui.NewModel(client)
+// resolveSubscription finds the subscription a query refers to. When it
+// matches several, the user picks one on a terminal; otherwise the
+// candidates are listed and azswitch exits with status 1.
+func resolveSubscription(ctx context.Context, client azure.Client, cfg *config.Config, subs []azure.Subscription, query string) (azure.Subscription, error) {
+	source := resolve.Source{
+		Subscriptions: subs,
+		Aliases:       cfg.Aliases,
+		Tenants:       func() ([]azure.Tenant, error) { return client.ListTenants(ctx) },
+	}
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("error running TUI: %w", err)
+	sub, err := source.Resolve(query)
+	var ambiguous *resolve.AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		return sub, err
 	}
 
-	return nil
+	options := make([]string, len(ambiguous.Candidates))
+	for i := range ambiguous.Candidates {
+		c := &ambiguous.Candidates[i]
+		options[i] = fmt.Sprintf("%s (%s, %s)", c.Name, c.ID, listing.TenantTitle(*c))
+	}
+
+	if stdinIsTerminal() {
+		i, ok := choose(ambiguous.Error()+":", options)
+		if !ok {
+			return azure.Subscription{}, notConfirmed()
+		}
+		return ambiguous.Candidates[i], nil
+	}
+
+	fmt.Fprintln(os.Stderr, ambiguous.Error()+":")
+	for _, option := range options {
+		fmt.Fprintf(os.Stderr, "  %s\n", option)
+	}
+	fmt.Fprintln(os.Stderr, "Use a longer query or the subscription ID")
+	return azure.Subscription{}, exitCodeError{code: 1}
+}
+
+// inlineHeight returns the height of the inline picker from --inline,
+// --height or the config, and whether inline mode is on.
+func inlineHeight(cfg *config.Config) (int, bool) {
+	if !flagInline && flagHeight <= 0 && !cfg.Behavior.Inline {
+		return 0, false
+	}
+	switch {
+	case flagHeight > 0:
+		return flagHeight, true
+	case cfg.Behavior.InlineHeight > 0:
+		return cfg.Behavior.InlineHeight, true
+	}
+	return config.DefaultInlineHeight, true
+}
+
+// printHookOutput prints hook output, if any.
+func printHookOutput(output string) {
+	if output != "" {
+		fmt.Println(output)
+	}
+}
+
+func runInteractive(client azure.Client, cfg *config.Config, runner *hooks.Runner, readOnly bool) error {
+	opts := []tui.Option{
+		tui.WithConfig(cfg),
+		tui.WithHooks(runner),
+		tui.WithKubernetes(cfg.Kubernetes),
+	}
+	if readOnly {
+		// The client is already read-only; this disables switching in the UI.
+		opts = append(opts, tui.WithReadOnly())
+	}
+	// Favorites added in the TUI are saved to the config file.
+	if path, err := configPath(); err == nil {
+		opts = append(opts, tui.WithConfigFile(path))
+	}
+
+	_, err := runProgram(client, cfg, opts, nil)
+	return err
+}
+
+// runProgram runs the TUI full-screen or inline as configured, and returns
+// its final model.
+func runProgram(client azure.Client, cfg *config.Config, opts []tui.Option, programOpts []tea.ProgramOption) (tui.Model, error) {
+	if path, err := history.DefaultPath(); err == nil {
+		opts = append(opts, tui.WithHistory(path))
+	}
+	if cfg.Tags.Enabled {
+		if path, err := tags.DefaultPath(); err == nil {
+			opts = append(opts, tui.WithTags(path))
+		}
+	}
+
+	// The inline picker does not own the screen, so mouse coordinates would
+	// not match its lines.
+	if height, ok := inlineHeight(cfg); ok {
+		opts = append(opts, tui.WithInline(height))
+	} else {
+		programOpts = append(programOpts, tea.WithAltScreen())
+		if !cfg.Behavior.DisableMouse {
+			programOpts = append(programOpts, tea.WithMouseCellMotion())
+		}
+	}
+
+	p := tea.NewProgram(tui.NewModel(client, opts...), programOpts...)
+	final, err := p.Run()
+	if err != nil {
+		return tui.Model{}, fmt.Errorf("error running TUI: %w", err)
+	}
+
+	return final.(tui.Model), nil
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/l2D/azswitch/internal/config"
+)
+
+func TestReadOnlyFlag_InheritedBySubcommands(t *testing.T) {
+	for _, cmd := range []string{"auto", "logout", "prune", "refresh-tokens", "switch-user"} {
+		sub, _, err := rootCmd.Find([]string{cmd})
+		if err != nil {
+			t.Fatalf("%s: %v", cmd, err)
+		}
+		if sub.InheritedFlags().Lookup("read-only") == nil {
+			t.Errorf("expected %s to accept --read-only", cmd)
+		}
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	cfg := config.Default()
+	if isReadOnly(cfg) {
+		t.Fatal("expected read-write by default")
+	}
+
+	cfg.Behavior.ReadOnly = true
+	if !isReadOnly(cfg) {
+		t.Error("expected behavior.read_only to make it read-only")
+	}
+
+	cfg.Behavior.ReadOnly = false
+	flagReadOnly = true
+	t.Cleanup(func() { flagReadOnly = false })
+	if !isReadOnly(cfg) {
+		t.Error("expected --read-only to make it read-only")
+	}
+}
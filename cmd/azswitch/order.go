@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/history"
+	"github.com/l2D/azswitch/internal/listing"
+)
+
+var (
+	flagSort  string
+	flagGroup string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagSort, "sort", "", "Sort subscriptions by "+strings.Join(config.Sorts, ", "))
+	rootCmd.PersistentFlags().StringVar(&flagGroup, "group", "", "Group subscriptions by "+strings.Join(config.Groups, ", "))
+}
+
+// applyListOrder overrides the config's list order with --sort and --group.
+func applyListOrder(cfg *config.Config) error {
+	if flagSort != "" {
+		if !slices.Contains(config.Sorts, flagSort) {
+			return fmt.Errorf("invalid --sort %q, must be one of %s", flagSort, strings.Join(config.Sorts, ", "))
+		}
+		cfg.List.Sort = flagSort
+	}
+	if flagGroup != "" {
+		if !slices.Contains(config.Groups, flagGroup) {
+			return fmt.Errorf("invalid --group %q, must be one of %s", flagGroup, strings.Join(config.Groups, ", "))
+		}
+		cfg.List.Group = flagGroup
+	}
+	return nil
+}
+
+// orderSubscriptions sorts and groups subscriptions as configured. The
+// recent and usage orders read the switch history.
+func orderSubscriptions(subs []azure.Subscription, list config.List) []listing.Group {
+	var usage *history.History
+	if list.Sort == config.SortRecent || list.Sort == config.SortUsage {
+		if path, err := history.DefaultPath(); err == nil {
+			usage, _ = history.Load(path)
+		}
+	}
+	return listing.Groups(listing.Sort(subs, list.Sort, usage), list.Group)
+}
+
+// recentFirst sorts subscriptions by when they were last switched to.
+func recentFirst(subs []azure.Subscription) []azure.Subscription {
+	return orderSubscriptions(subs, config.List{Sort: config.SortRecent})[0].Subscriptions
+}
+
+// recordSwitch adds a subscription switch to the history. A failure only
+// affects the recent and usage sort orders, so it is ignored.
+func recordSwitch(id string) {
+	if path, err := history.DefaultPath(); err == nil {
+		_ = history.Record(path, id)
+	}
+}
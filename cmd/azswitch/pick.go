@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var (
+	flagPickMulti  bool
+	flagPickFormat string
+)
+
+var pickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Choose a subscription or tenant and print its ID",
+	Long: `Open the selection UI on the terminal and print the ID of the chosen
+subscription, or of the chosen tenant when picked from the Directories tab.
+Nothing is switched. Output goes to stdout, so pick composes with other tools.
+
+--format takes a Go template executed for each picked item. Subscriptions
+have the fields ID, Name, State, TenantID, TenantDisplayName, CloudName and
+User.Name; tenants have TenantID, DisplayName and DefaultDomain.
+
+Exits with status 1 when cancelled.`,
+	Example: `  az group list --subscription $(azswitch pick)
+  azswitch pick --format '{{.Name}}'
+  azswitch pick --multi | xargs -n1 az account show --subscription`,
+	Args: cobra.NoArgs,
+	RunE: runPick,
+}
+
+func init() {
+	pickCmd.Flags().BoolVarP(&flagPickMulti, "multi", "m", false, "Mark several items with space and print all of them")
+	pickCmd.Flags().StringVarP(&flagPickFormat, "format", "f", "", "Go template to print for each item (default: the ID)")
+	rootCmd.AddCommand(pickCmd)
+}
+
+func runPick(_ *cobra.Command, _ []string) error {
+	var tmpl *template.Template
+	if flagPickFormat != "" {
+		var err error
+		tmpl, err = template.New("format").Option("missingkey=error").Parse(flagPickFormat)
+		if err != nil {
+			return fmt.Errorf("invalid --format: %w", err)
+		}
+	}
+
+	// stdout is usually captured, so the UI talks to the terminal directly.
+	tty, err := openTTYOutput()
+	if err != nil {
+		return fmt.Errorf("pick needs a terminal: %w", err)
+	}
+	defer tty.Close()
+	lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(tty))
+
+	ctx := context.Background()
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	final, err := runProgram(client, cfg,
+		[]tui.Option{tui.WithConfig(cfg), tui.WithPicker(flagPickMulti)},
+		[]tea.ProgramOption{tea.WithInputTTY(), tea.WithOutput(tty)},
+	)
+	if err != nil {
+		return err
+	}
+
+	subs, tenants := final.Picked()
+	if len(subs) == 0 && len(tenants) == 0 {
+		return exitCodeError{code: 1}
+	}
+
+	for i := range subs {
+		if err := printPicked(tmpl, &subs[i], subs[i].ID); err != nil {
+			return err
+		}
+	}
+	for i := range tenants {
+		if err := printPicked(tmpl, &tenants[i], tenants[i].TenantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printPicked prints a picked item's ID, or the template executed for it.
+func printPicked(tmpl *template.Template, item any, id string) error {
+	if tmpl == nil {
+		fmt.Println(id)
+		return nil
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, item); err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// openTTYOutput opens the controlling terminal for writing.
+func openTTYOutput() (*os.File, error) {
+	if runtime.GOOS == "windows" {
+		return os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	}
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stdin is shared by the prompts, so that lines typed ahead are not lost.
+var stdin = bufio.NewReader(os.Stdin)
+
+// stdinIsTerminal reports whether the prompts can be answered.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirm asks a yes or no question on stderr. An empty answer picks def.
+// Without a terminal to answer on, the answer is no.
+func confirm(question string, def bool) bool {
+	if !stdinIsTerminal() {
+		return false
+	}
+
+	choices := "[y/N]"
+	if def {
+		choices = "[Y/n]"
+	}
+	fmt.Fprintf(os.Stderr, "%s %s ", question, choices)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil {
+		return def
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// choose asks on stderr to pick one of options by number. It reports false
+// without a terminal to answer on, or when the answer is not a valid choice.
+func choose(question string, options []string) (int, bool) {
+	if !stdinIsTerminal() {
+		return 0, false
+	}
+
+	fmt.Fprintln(os.Stderr, question)
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+	fmt.Fprintf(os.Stderr, "Choose 1-%d: ", len(options))
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(options) {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// notConfirmed reports a declined confirmation and exits with status 1.
+func notConfirmed() error {
+	if !stdinIsTerminal() {
+		fmt.Fprintln(os.Stderr, "Not confirmed: run with --yes to confirm without a terminal")
+	} else {
+		fmt.Fprintln(os.Stderr, "Cancelled")
+	}
+	return exitCodeError{code: 1}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/tokens"
+)
+
+var (
+	flagPruneExpired bool
+	flagPruneDryRun  bool
+	flagPruneYes     bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove subscriptions of tenants that are no longer accessible",
+	Long: `Remove subscriptions from the Azure CLI profile whose tenant the current
+user can no longer access: tenants that az account tenant list no longer
+returns and, with --expired, tenants that only a new login would give a token
+for. Subscriptions of other signed-in users are left alone.
+
+Lists the subscriptions and asks before removing them unless --yes is given.`,
+	Example: `  azswitch prune --dry-run
+  azswitch prune --expired --yes`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&flagPruneExpired, "expired", false, "Also remove subscriptions of tenants that need a new login")
+	pruneCmd.Flags().BoolVar(&flagPruneDryRun, "dry-run", false, "Only list the subscriptions that would be removed")
+	pruneCmd.Flags().BoolVarP(&flagPruneYes, "yes", "y", false, "Do not ask for confirmation")
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+	if isReadOnly(cfg) && !flagPruneDryRun {
+		return fmt.Errorf("cannot prune: %w", azure.ErrReadOnly)
+	}
+
+	stale, reasons, err := staleSubscriptions(ctx, client)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		fmt.Println("Every subscription's tenant is accessible")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SUBSCRIPTION\tID\tTENANT\tREASON")
+	ids := make([]string, len(stale))
+	for i := range stale {
+		ids[i] = stale[i].ID
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", stale[i].Name, stale[i].ID, stale[i].TenantID, reasons[i])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if flagPruneDryRun {
+		return nil
+	}
+	if !flagPruneYes && !confirm(fmt.Sprintf("Remove %d subscriptions from the profile?", len(stale)), false) {
+		return notConfirmed()
+	}
+
+	if err := client.RemoveSubscriptions(ctx, ids); err != nil {
+		return fmt.Errorf("failed to remove subscriptions: %w", err)
+	}
+	fmt.Printf("Removed %d subscriptions\n", len(stale))
+	return nil
+}
+
+// staleSubscriptions returns the current user's subscriptions whose tenant is
+// no longer accessible, with the reason for each. The tenant list only covers
+// the current user, so other users' subscriptions are never stale.
+func staleSubscriptions(ctx context.Context, client azure.Client) ([]azure.Subscription, []string, error) {
+	account, err := client.GetCurrentAccount(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tenants, err := client.ListTenants(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accessible := make(map[string]bool, len(tenants))
+	for i := range tenants {
+		accessible[strings.ToLower(tenants[i].TenantID)] = true
+	}
+
+	needsLogin := make(map[string]bool)
+	if flagPruneExpired {
+		for _, status := range tokens.CheckAll(ctx, client, tenantIDs(tenants), time.Now()) {
+			needsLogin[strings.ToLower(status.TenantID)] = status.NeedsLogin()
+		}
+	}
+
+	var stale []azure.Subscription
+	var reasons []string
+	for i := range subs {
+		if !strings.EqualFold(subs[i].User.Name, account.User.Name) {
+			continue
+		}
+		tenant := strings.ToLower(subs[i].TenantID)
+		switch {
+		case !accessible[tenant]:
+			stale = append(stale, subs[i])
+			reasons = append(reasons, "tenant not accessible")
+		case needsLogin[tenant]:
+			stale = append(stale, subs[i])
+			reasons = append(reasons, "login required")
+		}
+	}
+	return stale, reasons, nil
+}
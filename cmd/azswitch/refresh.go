@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/tokens"
+)
+
+var (
+	flagRefreshLogin      bool
+	flagRefreshDeviceCode bool
+	flagRefreshYes        bool
+)
+
+var refreshTokensCmd = &cobra.Command{
+	Use:   "refresh-tokens",
+	Short: "Refresh the token of every tenant and log in where needed",
+	Long: `Acquire an access token for every tenant silently, as the Azure CLI does
+from its refresh tokens, and report the tenants that need an interactive login.
+
+With --login, azswitch then logs in to each of those tenants in turn, asking
+before each one. The login opens a browser, or prints a code to enter on
+another device with --device-code. The subscription that was active before is
+switched back to afterwards.
+
+Exits with status 1 if a tenant still needs a login.`,
+	Example: `  azswitch refresh-tokens
+  azswitch refresh-tokens --login
+  azswitch refresh-tokens --device-code --yes`,
+	Args: cobra.NoArgs,
+	RunE: runRefreshTokens,
+}
+
+func init() {
+	refreshTokensCmd.Flags().BoolVar(&flagRefreshLogin, "login", false, "Log in to each tenant that needs it, one at a time")
+	refreshTokensCmd.Flags().BoolVar(&flagRefreshDeviceCode, "device-code", false, "Log in with a device code instead of a browser (implies --login)")
+	refreshTokensCmd.Flags().BoolVarP(&flagRefreshYes, "yes", "y", false, "Log in without asking before each tenant")
+
+	rootCmd.AddCommand(refreshTokensCmd)
+}
+
+func runRefreshTokens(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	login := flagRefreshLogin || flagRefreshDeviceCode
+	if login && isReadOnly(cfg) {
+		return fmt.Errorf("cannot log in: %w", azure.ErrReadOnly)
+	}
+
+	tenants, err := client.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Refreshing tokens for %d tenants...\n", len(tenants))
+	statuses := tokens.CheckAll(ctx, client, tenantIDs(tenants), time.Now())
+
+	results := make([]string, len(statuses))
+	for i, status := range statuses {
+		switch status.State {
+		case tokens.Valid, tokens.Expiring:
+			results[i] = "refreshed"
+		case tokens.Expired:
+			results[i] = "needs login"
+		default:
+			results[i] = "check failed"
+		}
+	}
+
+	if login {
+		loginTenants(ctx, client, tenants, statuses, results)
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TENANT\tID\tTOKEN\tRESULT")
+	pending := 0
+	for i, status := range statuses {
+		if status.NeedsLogin() {
+			pending++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tenants[i].Title(), status.TenantID, styleToken(status, now), results[i])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		if !login {
+			fmt.Fprintf(os.Stderr, "\n%d tenants need a login. Run: azswitch refresh-tokens --login\n", pending)
+		}
+		return exitCodeError{code: 1}
+	}
+	return nil
+}
+
+// loginTenants logs in to each tenant whose token needs it, asking first
+// unless --yes is set, and updates their statuses and results.
+func loginTenants(ctx context.Context, client azure.Client, tenants []azure.Tenant, statuses []tokens.Status, results []string) {
+	original, _ := client.GetCurrentAccount(ctx)
+
+	// Tenant-level logins also cover tenants without subscriptions.
+	opts := onTerminal(azure.LoginOptions{AllowNoSubscriptions: true, UseDeviceCode: flagRefreshDeviceCode})
+
+	loggedIn := false
+	for i, status := range statuses {
+		if !status.NeedsLogin() {
+			continue
+		}
+
+		question := fmt.Sprintf("Log in to %s (%s)?", tenants[i].Title(), status.TenantID)
+		if !flagRefreshYes && !confirm(question, true) {
+			results[i] = "skipped"
+			continue
+		}
+
+		if err := client.LoginToTenant(ctx, status.TenantID, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Login to %s failed: %v\n", tenants[i].Title(), err)
+			results[i] = "login failed"
+			continue
+		}
+		loggedIn = true
+		statuses[i] = tokens.Check(ctx, client, status.TenantID, time.Now())
+		results[i] = "logged in"
+	}
+
+	// Each login makes a subscription of its tenant the default.
+	if loggedIn && original != nil {
+		if err := client.SetSubscription(ctx, original.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not switch back to %s: %v\n", original.Name, err)
+		}
+	}
+}
+
+// onTerminal attaches a login to the terminal, so that the browser prompt or
+// device code reaches the user.
+func onTerminal(opts azure.LoginOptions) azure.LoginOptions {
+	opts.Stdin, opts.Stdout, opts.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return opts
+}
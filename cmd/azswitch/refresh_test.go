@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/tokens"
+)
+
+// loginClient returns a mock client signed in to sub-1, on which logging in
+// to "broken" fails and every token is valid afterwards.
+func loginClient() *azure.MockClient {
+	client := azure.NewMockClient()
+	client.GetCurrentAccountFunc = func(_ context.Context) (*azure.Account, error) {
+		return &azure.Account{Name: "Sub 1", ID: "sub-1", TenantID: "t1"}, nil
+	}
+	client.LoginToTenantFunc = func(_ context.Context, tenantID string, _ azure.LoginOptions) error {
+		if tenantID == "broken" {
+			return fmt.Errorf("%w: AADSTS50076", azure.ErrCommandFailed)
+		}
+		return nil
+	}
+	client.GetAccessTokenFunc = func(_ context.Context, tenantID string) (*azure.AccessToken, error) {
+		return &azure.AccessToken{Tenant: tenantID, ExpiresOn: time.Now().Add(time.Hour).Unix()}, nil
+	}
+	return client
+}
+
+// expiredTenants returns tenants with the given IDs whose tokens have expired.
+func expiredTenants(ids ...string) ([]azure.Tenant, []tokens.Status, []string) {
+	tenants := make([]azure.Tenant, len(ids))
+	statuses := make([]tokens.Status, len(ids))
+	for i, id := range ids {
+		tenants[i] = azure.Tenant{TenantID: id, DisplayName: strings.ToUpper(id)}
+		statuses[i] = tokens.Status{TenantID: id, State: tokens.Expired}
+	}
+	return tenants, statuses, make([]string, len(ids))
+}
+
+// withoutTerminal gives the test a stdin that is not a terminal, on which
+// nothing is confirmed.
+func withoutTerminal(t *testing.T) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	saved := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = saved
+		r.Close()
+	})
+}
+
+// answerYes sets --yes for the test.
+func answerYes(t *testing.T) {
+	t.Helper()
+	flagRefreshYes = true
+	t.Cleanup(func() { flagRefreshYes = false })
+}
+
+func TestLoginTenants_SkipsWithoutConfirmation(t *testing.T) {
+	withoutTerminal(t)
+	client := loginClient()
+	tenants, statuses, results := expiredTenants("t2")
+
+	loginTenants(context.Background(), client, tenants, statuses, results)
+
+	if results[0] != "skipped" || !statuses[0].NeedsLogin() {
+		t.Errorf("expected t2 to be skipped, got %q with %+v", results[0], statuses[0])
+	}
+	if len(client.Calls.LoginToTenant) != 0 || len(client.Calls.SetSubscription) != 0 {
+		t.Errorf("expected no login or switch, got %v and %v", client.Calls.LoginToTenant, client.Calls.SetSubscription)
+	}
+}
+
+func TestLoginTenants_LogsInAndSwitchesBack(t *testing.T) {
+	answerYes(t)
+	client := loginClient()
+	tenants, statuses, results := expiredTenants("t2", "broken", "t3")
+	statuses[2].State = tokens.Valid
+
+	loginTenants(context.Background(), client, tenants, statuses, results)
+
+	if strings.Join(client.Calls.LoginToTenant, ",") != "t2,broken" {
+		t.Errorf("expected logins to the expired tenants, got %v", client.Calls.LoginToTenant)
+	}
+	if results[0] != "logged in" || statuses[0].State != tokens.Valid {
+		t.Errorf("expected t2 to be logged in and checked again, got %q with %+v", results[0], statuses[0])
+	}
+	if results[1] != "login failed" || !statuses[1].NeedsLogin() {
+		t.Errorf("expected the broken login to fail, got %q with %+v", results[1], statuses[1])
+	}
+	if results[2] != "" {
+		t.Errorf("expected the valid tenant to be left alone, got %q", results[2])
+	}
+	if strings.Join(client.Calls.SetSubscription, ",") != "sub-1" {
+		t.Errorf("expected a switch back to sub-1, got %v", client.Calls.SetSubscription)
+	}
+}
+
+func TestLoginTenants_NoSwitchBackWithoutLogin(t *testing.T) {
+	answerYes(t)
+	client := loginClient()
+	tenants, statuses, results := expiredTenants("broken")
+
+	loginTenants(context.Background(), client, tenants, statuses, results)
+
+	if results[0] != "login failed" {
+		t.Errorf("expected the login to fail, got %q", results[0])
+	}
+	if len(client.Calls.SetSubscription) != 0 {
+		t.Errorf("expected no switch back after failed logins, got %v", client.Calls.SetSubscription)
+	}
+}
+
+func TestLoginTenants_LogsInOnTerminal(t *testing.T) {
+	answerYes(t)
+	client := loginClient()
+	var got azure.LoginOptions
+	client.LoginToTenantFunc = func(_ context.Context, _ string, opts azure.LoginOptions) error {
+		got = opts
+		return errors.New("cancelled")
+	}
+	tenants, statuses, results := expiredTenants("t2")
+
+	loginTenants(context.Background(), client, tenants, statuses, results)
+
+	if !got.AllowNoSubscriptions || got.Stdin == nil || got.Stdout == nil || got.Stderr == nil {
+		t.Errorf("expected a tenant-level login on the terminal, got %+v", got)
+	}
+}
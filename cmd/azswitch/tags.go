@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/listing"
+	"github.com/l2D/azswitch/internal/tags"
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var flagTagsRefresh bool
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Show the tags of every subscription",
+	Long: `Show the Azure Resource Manager tags of every subscription, which az account
+list does not return. Tags are fetched with az rest and cached; with tags
+enabled in the config file they are also shown in the TUI and --list, and
+filters can match them, e.g. tag.env=prod.
+
+Cached tags are used until the configured ttl passes. --refresh fetches them
+all again.`,
+	Example: `  azswitch tags
+  azswitch tags --refresh`,
+	Args: cobra.NoArgs,
+	RunE: runTags,
+}
+
+func init() {
+	tagsCmd.Flags().BoolVar(&flagTagsRefresh, "refresh", false, "Fetch every subscription's tags, ignoring the cache")
+
+	rootCmd.AddCommand(tagsCmd)
+}
+
+func runTags(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	path, err := tags.DefaultPath()
+	if err != nil {
+		return err
+	}
+	opts := tags.Options{TTL: cfg.Tags.TTL, Force: flagTagsRefresh}
+	if err := tags.Enrich(ctx, client, path, subs, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", tui.WarningStyle.Render("Warning:"), err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SUBSCRIPTION\tID\tTAGS")
+	for _, sub := range listing.Sort(subs, config.SortName, nil) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", sub.Name, sub.ID, strings.Join(tags.Format(sub.Tags, nil), " "))
+	}
+	return w.Flush()
+}
+
+// enrichTags sets the tags of the subscriptions when tags are enabled. A
+// failure to fetch some is only a warning, as they are cosmetic unless a
+// filter needs them.
+func enrichTags(ctx context.Context, client azure.Client, cfg *config.Config, subs []azure.Subscription) {
+	if !cfg.Tags.Enabled {
+		return
+	}
+	path, err := tags.DefaultPath()
+	if err == nil {
+		err = tags.Enrich(ctx, client, path, subs, tags.Options{TTL: cfg.Tags.TTL})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed to fetch tags: %v\n", tui.WarningStyle.Render("Warning:"), err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var (
+	flagTheme   string
+	flagNoColor bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagTheme, "theme", "", "Color theme (auto, dark, light, high-contrast, monochrome)")
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colors (also set by the NO_COLOR environment variable)")
+}
+
+// applyTheme styles TUI and CLI output from --theme or the config, and turns
+// colors off for --no-color or NO_COLOR.
+func applyTheme(cfg *config.Config) error {
+	if flagNoColor || tui.NoColorRequested() {
+		tui.DisableColor()
+	}
+
+	if flagTheme != "" {
+		cfg.Theme = flagTheme
+	}
+	return tui.ApplyTheme(cfg.Theme)
+}
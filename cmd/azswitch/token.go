@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/tokens"
+	"github.com/l2D/azswitch/internal/tui"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Inspect the Azure CLI's access tokens",
+}
+
+var tokenStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which tenants have valid, expiring or expired tokens",
+	Long: `Acquire an access token for every tenant, as the Azure CLI does before
+each command, and show how long it stays valid. Tokens are refreshed silently
+where possible, so a tenant shown as needing a login needs az login --tenant.`,
+	Args: cobra.NoArgs,
+	RunE: runTokenStatus,
+}
+
+func init() {
+	tokenCmd.AddCommand(tokenStatusCmd)
+	rootCmd.AddCommand(tokenCmd)
+}
+
+func runTokenStatus(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	_, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenants, err := client.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	statuses := tokens.CheckAll(ctx, client, tenantIDs(tenants), now)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TENANT\tID\tTOKEN")
+	for i, status := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", tenants[i].Title(), status.TenantID, styleToken(status, now))
+	}
+	return w.Flush()
+}
+
+// tenantIDs returns the IDs of tenants.
+func tenantIDs(tenants []azure.Tenant) []string {
+	ids := make([]string, len(tenants))
+	for i := range tenants {
+		ids[i] = tenants[i].TenantID
+	}
+	return ids
+}
+
+// styleToken describes a token status, colored by how urgent it is.
+func styleToken(status tokens.Status, now time.Time) string {
+	label := status.Label(now)
+	switch status.State {
+	case tokens.Valid:
+		return tui.SuccessStyle.Render(label)
+	case tokens.Expiring:
+		return tui.WarningStyle.Render(label)
+	case tokens.Expired:
+		return tui.ErrorStyle.Render(label)
+	}
+	return tui.MutedStyle.Render(label)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/hooks"
+	"github.com/l2D/azswitch/internal/resolve"
+)
+
+var flagSwitchUser string
+
+var switchUserCmd = &cobra.Command{
+	Use:   "switch-user [subscription]",
+	Short: "Use another signed-in user's credentials for a subscription",
+	Long: `Switch to a subscription, the current one by default, using the
+credentials of another user signed in to the Azure CLI. With several other
+users, --user picks one, or azswitch asks on a terminal.
+
+If the Azure CLI holds no token of that user for the subscription's tenant,
+az login opens for the tenant: sign in as that user.`,
+	Example: `  azswitch switch-user
+  azswitch switch-user Production --user admin@contoso.com`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSwitchUser,
+}
+
+func init() {
+	switchUserCmd.Flags().StringVarP(&flagSwitchUser, "user", "u", "", "User to switch to")
+
+	rootCmd.AddCommand(switchUserCmd)
+}
+
+func runSwitchUser(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, client, err := setup(ctx)
+	if err != nil {
+		return err
+	}
+	if isReadOnly(cfg) {
+		return fmt.Errorf("cannot switch user: %w", azure.ErrReadOnly)
+	}
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var sub azure.Subscription
+	if len(args) == 1 {
+		if sub, err = resolveSubscription(ctx, client, cfg, subs, args[0]); err != nil {
+			return err
+		}
+	} else {
+		account, err := client.GetCurrentAccount(ctx)
+		if err != nil {
+			return err
+		}
+		if sub, err = (resolve.Source{Subscriptions: subs}).Resolve(account.ID); err != nil {
+			return err
+		}
+	}
+
+	user, err := pickUser(subs, sub)
+	if err != nil {
+		return err
+	}
+
+	ev := hooks.Event{
+		Kind:                hooks.KindSubscription,
+		NewSubscriptionID:   sub.ID,
+		NewSubscriptionName: sub.Name,
+		NewTenantID:         sub.TenantID,
+	}
+	return runSwitch(ctx, client, hooks.NewRunner(cfg.Hooks), ev, func() error {
+		err := azure.SwitchUser(ctx, client, sub, user)
+		if errors.Is(err, azure.ErrAuthRequired) {
+			fmt.Printf("No token of %s for this tenant. Sign in as %s...\n", user.Name, user.Name)
+			if err := client.LoginToTenant(ctx, sub.TenantID, onTerminal(azure.LoginOptions{})); err != nil {
+				return fmt.Errorf("failed to log in: %w", err)
+			}
+			err = client.SetSubscription(ctx, sub.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to switch user: %w", err)
+		}
+		recordSwitch(sub.ID)
+		fmt.Printf("Switched to %s as %s\n", sub.Name, user.Name)
+		return nil
+	})
+}
+
+// pickUser returns the user to switch a subscription to: --user, the only
+// other signed-in user, or one chosen on the terminal.
+func pickUser(subs []azure.Subscription, sub azure.Subscription) (azure.User, error) {
+	users := azure.Users(subs)
+	if flagSwitchUser != "" {
+		for _, user := range users {
+			if strings.EqualFold(user.Name, flagSwitchUser) {
+				return user, nil
+			}
+		}
+		// A user without subscriptions yet is signed in by the login.
+		return azure.User{Name: flagSwitchUser, Type: "user"}, nil
+	}
+
+	var others []azure.User
+	var names []string
+	for _, user := range users {
+		if !strings.EqualFold(user.Name, sub.User.Name) {
+			others = append(others, user)
+			names = append(names, user.Name)
+		}
+	}
+
+	switch len(others) {
+	case 0:
+		return azure.User{}, fmt.Errorf("no other user is signed in: sign in with az login, or name one with --user")
+	case 1:
+		return others[0], nil
+	}
+
+	i, ok := choose(fmt.Sprintf("Switch %s to which user?", sub.Name), names)
+	if !ok {
+		return azure.User{}, fmt.Errorf("several users are signed in, pick one with --user: %s", strings.Join(names, ", "))
+	}
+	return others[i], nil
+}
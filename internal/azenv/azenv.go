@@ -0,0 +1,180 @@
+// Package azenv prepares isolated Azure CLI environments, so commands can run
+// against a subscription without changing the user's default.
+package azenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// EnvBaseConfigDir records the user's own Azure CLI config directory inside an
+// isolated environment, so nested environments copy from it rather than from
+// another isolated directory.
+const EnvBaseConfigDir = "AZSWITCH_BASE_AZURE_CONFIG_DIR"
+
+// EnvSubscriptionName holds the name of the subscription an environment targets.
+const EnvSubscriptionName = "AZSWITCH_SUBSCRIPTION_NAME"
+
+// Env is an isolated Azure CLI config directory whose default subscription
+// is the target subscription. Close removes it.
+type Env struct {
+	// Dir is the isolated Azure CLI config directory.
+	Dir string
+
+	// Subscription is the subscription the environment targets.
+	Subscription azure.Subscription
+
+	// base is the config directory the environment was copied from.
+	base string
+}
+
+// New copies the user's Azure CLI config directory into a temporary directory
+// and makes sub its default subscription. Credentials refreshed inside the
+// isolated directory are not written back.
+func New(sub azure.Subscription) (*Env, error) {
+	src, err := BaseConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewFrom(src, sub)
+}
+
+// BaseConfigDir returns the user's own Azure CLI config directory, even when
+// called from inside an isolated environment.
+func BaseConfigDir() (string, error) {
+	if dir := os.Getenv(EnvBaseConfigDir); dir != "" {
+		return dir, nil
+	}
+	return azure.ConfigDir()
+}
+
+// NewFrom is like New but copies the given Azure CLI config directory.
+func NewFrom(src string, sub azure.Subscription) (*Env, error) {
+	dir, err := os.MkdirTemp("", "azswitch-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create isolated Azure config: %w", err)
+	}
+
+	env := &Env{Dir: dir, Subscription: sub, base: src}
+	if err := env.populate(src); err != nil {
+		_ = env.Close()
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// NewPersistent is like New but uses a stable per-subscription directory
+// under the user cache directory, so the environment can outlive azswitch,
+// for example when exported into a shell. It is refreshed on every call.
+func NewPersistent(sub azure.Subscription) (*Env, error) {
+	src, err := BaseConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+
+	dir := filepath.Join(cache, "azswitch", "env", sub.ID)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to reset isolated Azure config: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create isolated Azure config: %w", err)
+	}
+
+	env := &Env{Dir: dir, Subscription: sub, base: src}
+	if err := env.populate(src); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// Environ returns the environment variables that point the Azure CLI and
+// Azure SDKs (including Terraform's azurerm provider) at the subscription.
+func (e *Env) Environ() []string {
+	return []string{
+		azure.EnvConfigDir + "=" + e.Dir,
+		EnvBaseConfigDir + "=" + e.base,
+		EnvSubscriptionName + "=" + e.Subscription.Name,
+		"AZURE_SUBSCRIPTION_ID=" + e.Subscription.ID,
+		"AZURE_TENANT_ID=" + e.Subscription.TenantID,
+		"ARM_SUBSCRIPTION_ID=" + e.Subscription.ID,
+		"ARM_TENANT_ID=" + e.Subscription.TenantID,
+	}
+}
+
+// Close removes the isolated config directory.
+func (e *Env) Close() error {
+	return os.RemoveAll(e.Dir)
+}
+
+// populate copies the top-level files of src and sets the default subscription.
+func (e *Env) populate(src string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("failed to read Azure CLI config directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(e.Dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	profile, err := azure.LoadProfile(e.Dir)
+	if err != nil {
+		return err
+	}
+	if err := profile.SetDefault(e.Subscription.ID); err != nil {
+		return err
+	}
+	return profile.Save()
+}
+
+// copyFile copies a single file, readable only by the current user.
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to copy Azure CLI config: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to copy Azure CLI config: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to copy Azure CLI config: %w", err)
+	}
+	return nil
+}
+
+// VariableNames returns the names of the variables set by Environ.
+func VariableNames() []string {
+	return []string{
+		azure.EnvConfigDir,
+		EnvBaseConfigDir,
+		EnvSubscriptionName,
+		"AZURE_SUBSCRIPTION_ID",
+		"AZURE_TENANT_ID",
+		"ARM_SUBSCRIPTION_ID",
+		"ARM_TENANT_ID",
+	}
+}
@@ -0,0 +1,78 @@
+package azenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+func TestNewFrom_IsolatesDefaultSubscription(t *testing.T) {
+	src := filepath.Join("testdata", "azure")
+	before, _ := os.ReadFile(filepath.Join(src, azure.ProfileFile))
+
+	env, err := NewFrom(src, azure.Subscription{ID: "sub-2", TenantID: "tenant-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer env.Close()
+
+	if _, err := os.Stat(filepath.Join(env.Dir, "msal_token_cache.json")); err != nil {
+		t.Errorf("expected token cache to be copied: %v", err)
+	}
+
+	// The isolated profile defaults to the target subscription...
+	isolated, err := azure.LoadProfile(env.Dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(isolated.SubscriptionIDs(), []string{"sub-1", "sub-2"}) {
+		t.Errorf("unexpected subscriptions %v", isolated.SubscriptionIDs())
+	}
+	if isolated.DefaultSubscriptionID() != "sub-2" {
+		t.Errorf("expected sub-2 to be the default, got '%s'", isolated.DefaultSubscriptionID())
+	}
+
+	// ...while the source profile is untouched.
+	after, _ := os.ReadFile(filepath.Join(src, azure.ProfileFile))
+	if string(before) != string(after) {
+		t.Error("expected source profile to be unchanged")
+	}
+}
+
+func TestNewFrom_UnknownSubscription(t *testing.T) {
+	_, err := NewFrom(filepath.Join("testdata", "azure"), azure.Subscription{ID: "sub-3"})
+	if !errors.Is(err, azure.ErrSubscriptionNotInProfile) {
+		t.Errorf("expected ErrSubscriptionNotInProfile, got %v", err)
+	}
+}
+
+func TestEnv_EnvironAndClose(t *testing.T) {
+	env, err := NewFrom(filepath.Join("testdata", "azure"), azure.Subscription{ID: "sub-1", Name: "Sub 1", TenantID: "tenant-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	environ := env.Environ()
+	for _, want := range []string{
+		"AZURE_CONFIG_DIR=" + env.Dir,
+		"ARM_SUBSCRIPTION_ID=sub-1",
+		"ARM_TENANT_ID=tenant-1",
+		"AZURE_SUBSCRIPTION_ID=sub-1",
+		"AZSWITCH_SUBSCRIPTION_NAME=Sub 1",
+	} {
+		if !slices.Contains(environ, want) {
+			t.Errorf("expected %q in environment", want)
+		}
+	}
+
+	if err := env.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(env.Dir); !os.IsNotExist(err) {
+		t.Error("expected isolated directory to be removed")
+	}
+}
@@ -0,0 +1,403 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultARMBaseURL is the Azure Resource Manager endpoint of the public cloud.
+const DefaultARMBaseURL = "https://management.azure.com"
+
+// ErrRequestFailed is wrapped by failed Azure Resource Manager requests.
+var ErrRequestFailed = errors.New("azure resource manager request failed")
+
+// tenantsAPIVersion is the ARM API version of tenant requests.
+const tenantsAPIVersion = "2022-12-01"
+
+// defaultARMTimeout bounds each request of the default HTTP client.
+const defaultARMTimeout = 30 * time.Second
+
+// armParallel is the number of tenants whose subscriptions are listed at once.
+const armParallel = 4
+
+// ARMClient implements Client by calling Azure Resource Manager directly to
+// list subscriptions and tenants, with fresh names, states and tags. Access
+// tokens are obtained from the wrapped client, one per tenant, and reused
+// until they expire. Everything else, including switching, logins and the
+// current account, goes through the wrapped client, since it changes or
+// reads the Azure CLI's profile.
+//
+// ARM does not know which user signed in to a subscription or which one is
+// the default, so listed subscriptions are merged with the wrapped client's.
+type ARMClient struct {
+	client     Client
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]*AccessToken
+}
+
+// ARMOption configures an ARMClient.
+type ARMOption func(*ARMClient)
+
+// WithBaseURL sends requests to another ARM endpoint, such as a sovereign
+// cloud's or a test server's.
+func WithBaseURL(baseURL string) ARMOption {
+	return func(c *ARMClient) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sends requests with the given HTTP client.
+func WithHTTPClient(httpClient *http.Client) ARMOption {
+	return func(c *ARMClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry retries throttled (429) and failed (5xx) requests up to retries
+// times, waiting backoff before the first retry and twice as long before each
+// next one, unless the response says how long to wait in Retry-After.
+func WithRetry(retries int, backoff time.Duration) ARMOption {
+	return func(c *ARMClient) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// NewARMClient creates a client that lists from Azure Resource Manager and
+// uses client for everything else, including the access token.
+func NewARMClient(client Client, opts ...ARMOption) *ARMClient {
+	c := &ARMClient{
+		client:     client,
+		baseURL:    DefaultARMBaseURL,
+		httpClient: &http.Client{Timeout: defaultARMTimeout},
+		retries:    3,
+		backoff:    500 * time.Millisecond,
+		tokens:     make(map[string]*AccessToken),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// armSubscription is a subscription as returned by ARM.
+type armSubscription struct {
+	SubscriptionID   string            `json:"subscriptionId"`
+	DisplayName      string            `json:"displayName"`
+	State            string            `json:"state"`
+	TenantID         string            `json:"tenantId"`
+	ManagedByTenants []any             `json:"managedByTenants"`
+	Tags             map[string]string `json:"tags"`
+}
+
+// page is one page of an ARM list response.
+type page[T any] struct {
+	Value    []T    `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// armError is the error body of a failed ARM request.
+type armError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CheckCLI implements Client.
+func (c *ARMClient) CheckCLI(ctx context.Context) error {
+	return c.client.CheckCLI(ctx)
+}
+
+// CheckLogin implements Client.
+func (c *ARMClient) CheckLogin(ctx context.Context) error {
+	return c.client.CheckLogin(ctx)
+}
+
+// GetCurrentAccount implements Client.
+func (c *ARMClient) GetCurrentAccount(ctx context.Context) (*Account, error) {
+	return c.client.GetCurrentAccount(ctx)
+}
+
+// ListSubscriptions lists the subscriptions of every tenant from ARM, each
+// with a token of its tenant, and merges them with the wrapped client's:
+// users and the default come from there, and the subscriptions of tenants
+// ARM cannot list, such as those needing a login or signed in to as another
+// user, are kept as the wrapped client lists them. If ARM cannot list the
+// tenants, the wrapped client's subscriptions are returned as they are.
+func (c *ARMClient) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	known, err := c.client.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenants, err := c.ListTenants(ctx)
+	if err != nil {
+		return known, nil
+	}
+
+	listed := make([][]armSubscription, len(tenants))
+	sem := make(chan struct{}, armParallel)
+	var wg sync.WaitGroup
+	for i := range tenants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// A tenant that cannot be listed keeps the known subscriptions.
+			listed[i], _ = list[armSubscription](ctx, c, tenants[i].TenantID, "/subscriptions?api-version="+subscriptionsAPIVersion)
+		}()
+	}
+	wg.Wait()
+
+	// The tokens are the current user's, so new subscriptions are too.
+	subs := slices.Clone(known)
+	index := make(map[string]int, len(subs))
+	var user User
+	for i := range subs {
+		index[strings.ToLower(subs[i].ID)] = i
+		if subs[i].IsDefault {
+			user = subs[i].User
+		}
+	}
+
+	for i := range tenants {
+		tenant := &tenants[i]
+		for _, s := range listed[i] {
+			if j, ok := index[strings.ToLower(s.SubscriptionID)]; ok {
+				sub := &subs[j]
+				sub.Name, sub.State, sub.Tags = s.DisplayName, s.State, s.Tags
+				if sub.TenantDisplayName == "" {
+					sub.TenantDisplayName = tenant.DisplayName
+				}
+				continue
+			}
+
+			index[strings.ToLower(s.SubscriptionID)] = len(subs)
+			subs = append(subs, Subscription{
+				HomeTenantID:      s.TenantID,
+				ID:                s.SubscriptionID,
+				ManagedByTenants:  s.ManagedByTenants,
+				Name:              s.DisplayName,
+				State:             s.State,
+				TenantDisplayName: tenant.DisplayName,
+				TenantID:          tenant.TenantID,
+				User:              user,
+				Tags:              s.Tags,
+			})
+		}
+	}
+	return subs, nil
+}
+
+// ListTenants lists the tenants the signed-in user can access from ARM.
+func (c *ARMClient) ListTenants(ctx context.Context) ([]Tenant, error) {
+	return list[Tenant](ctx, c, "", "/tenants?api-version="+tenantsAPIVersion)
+}
+
+// SetSubscription implements Client.
+func (c *ARMClient) SetSubscription(ctx context.Context, subscriptionIDOrName string) error {
+	return c.client.SetSubscription(ctx, subscriptionIDOrName)
+}
+
+// SetSubscriptionUser implements Client.
+func (c *ARMClient) SetSubscriptionUser(ctx context.Context, subscriptionID string, user User) error {
+	return c.client.SetSubscriptionUser(ctx, subscriptionID, user)
+}
+
+// GetSubscriptionTags implements Client. The wrapped client is used, since
+// the subscription may belong to another tenant than the token.
+func (c *ARMClient) GetSubscriptionTags(ctx context.Context, subscriptionID string) (map[string]string, error) {
+	return c.client.GetSubscriptionTags(ctx, subscriptionID)
+}
+
+// LoginToTenant implements Client.
+func (c *ARMClient) LoginToTenant(ctx context.Context, tenantID string, opts LoginOptions) error {
+	return c.client.LoginToTenant(ctx, tenantID, opts)
+}
+
+// Logout implements Client.
+func (c *ARMClient) Logout(ctx context.Context, username string) error {
+	return c.client.Logout(ctx, username)
+}
+
+// LogoutAll implements Client.
+func (c *ARMClient) LogoutAll(ctx context.Context) error {
+	return c.client.LogoutAll(ctx)
+}
+
+// RemoveSubscriptions implements Client.
+func (c *ARMClient) RemoveSubscriptions(ctx context.Context, ids []string) error {
+	return c.client.RemoveSubscriptions(ctx, ids)
+}
+
+// GetAccessToken implements Client.
+func (c *ARMClient) GetAccessToken(ctx context.Context, tenantID string) (*AccessToken, error) {
+	return c.client.GetAccessToken(ctx, tenantID)
+}
+
+// ListAKSClusters implements Client.
+func (c *ARMClient) ListAKSClusters(ctx context.Context, subscriptionID string) ([]AKSCluster, error) {
+	return c.client.ListAKSClusters(ctx, subscriptionID)
+}
+
+// GetAKSCredentials implements Client.
+func (c *ARMClient) GetAKSCredentials(ctx context.Context, cluster AKSCluster, kubeconfigPath string) error {
+	return c.client.GetAKSCredentials(ctx, cluster, kubeconfigPath)
+}
+
+// accessToken returns the token of a tenant, or of the current tenant if
+// tenantID is empty, obtained from the wrapped client on first use and again
+// only once it is about to expire.
+func (c *ARMClient) accessToken(ctx context.Context, tenantID string) (*AccessToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if token := c.tokens[tenantID]; token != nil && time.Until(token.Expiry()) > time.Minute {
+		return token, nil
+	}
+
+	token, err := c.client.GetAccessToken(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	c.tokens[tenantID] = token
+	return token, nil
+}
+
+// dropToken forgets a token that ARM rejected, so the next request obtains
+// a new one.
+func (c *ARMClient) dropToken(tenantID string, token *AccessToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens[tenantID] == token {
+		delete(c.tokens, tenantID)
+	}
+}
+
+// list gets every page of an ARM list with a token of the tenant, following
+// nextLink.
+func list[T any](ctx context.Context, c *ARMClient, tenantID, path string) ([]T, error) {
+	var items []T
+	for link := c.baseURL + path; link != ""; {
+		body, err := c.get(ctx, tenantID, link)
+		if err != nil {
+			return nil, err
+		}
+
+		var p page[T]
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		items = append(items, p.Value...)
+
+		if p.NextLink != "" && !c.sameHost(p.NextLink) {
+			return nil, fmt.Errorf("%w: next page %s is not on %s", ErrRequestFailed, p.NextLink, c.baseURL)
+		}
+		link = p.NextLink
+	}
+	return items, nil
+}
+
+// sameHost reports whether link points at the base URL's host, so that the
+// token is never sent elsewhere.
+func (c *ARMClient) sameHost(link string) bool {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return false
+	}
+	next, err := url.Parse(link)
+	return err == nil && next.Scheme == base.Scheme && next.Host == base.Host
+}
+
+// get sends a GET request authenticated for the tenant, retrying throttled
+// and failed ones, and returns the response body.
+func (c *ARMClient) get(ctx context.Context, tenantID, link string) ([]byte, error) {
+	token, err := c.accessToken(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRequestFailed, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRequestFailed, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRequestFailed, err)
+		}
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return body, nil
+		case resp.StatusCode == http.StatusUnauthorized:
+			c.dropToken(tenantID, token)
+			return nil, fmt.Errorf("%w: %w: %s", ErrRequestFailed, ErrAuthRequired, errorMessage(resp, body))
+		case retryable(resp.StatusCode) && attempt < c.retries:
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrRequestFailed, errorMessage(resp, body))
+		}
+	}
+}
+
+// retryable reports whether a request that failed with the status may
+// succeed when sent again.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter returns the wait asked for by a Retry-After header in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// errorMessage describes a failed response from its ARM error body, or its
+// status if it has none.
+func errorMessage(resp *http.Response, body []byte) string {
+	var e armError
+	if err := json.Unmarshal(body, &e); err == nil && e.Error.Message != "" {
+		return fmt.Sprintf("%s (%s): %s", resp.Status, e.Error.Code, e.Error.Message)
+	}
+	return resp.Status
+}
+
+// Ensure ARMClient implements Client.
+var _ Client = (*ARMClient)(nil)
@@ -0,0 +1,230 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// armServer serves ARM list responses for the handlers by path, accepting
+// bearer tokens that start with "token", as the mock clients' do.
+func armServer(t *testing.T, handlers map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token") {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"code":"InvalidAuthenticationToken","message":"The access token is invalid."}}`)
+			return
+		}
+		handler, ok := handlers[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// armClient returns an ARMClient for the server that retries without waiting.
+func armClient(srv *httptest.Server, client Client) *ARMClient {
+	return NewARMClient(client, WithBaseURL(srv.URL), WithHTTPClient(srv.Client()), WithRetry(2, time.Millisecond))
+}
+
+func TestARMClient_ListSubscriptions_MergesTenants(t *testing.T) {
+	var srv *httptest.Server
+	srv = armServer(t, map[string]http.HandlerFunc{
+		"/subscriptions": func(w http.ResponseWriter, r *http.Request) {
+			switch token := r.Header.Get("Authorization"); {
+			case token == "Bearer token-t1" && r.URL.Query().Get("page") == "":
+				fmt.Fprintf(w, `{"value":[{"subscriptionId":"S1","displayName":"Sub 1 renamed","state":"Enabled","tenantId":"t1","tags":{"env":"prod"}}],
+					"nextLink":"%s/subscriptions?api-version=2022-12-01&page=2"}`, srv.URL)
+			case token == "Bearer token-t1":
+				fmt.Fprint(w, `{"value":[{"subscriptionId":"s3","displayName":"Sub 3","state":"Enabled","tenantId":"t1"}]}`)
+			case token == "Bearer token-t2":
+				fmt.Fprint(w, `{"value":[{"subscriptionId":"s2","displayName":"Sub 2","state":"Disabled","tenantId":"customer"}]}`)
+			default:
+				t.Errorf("unexpected token %s", token)
+			}
+		},
+		"/tenants": func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, `{"value":[{"tenantId":"t1","displayName":"Tenant 1"},{"tenantId":"t2","displayName":"Tenant 2"},{"tenantId":"t3","displayName":"Tenant 3"}]}`)
+		},
+	})
+
+	alice, bob := User{Name: "alice@contoso.com", Type: "user"}, User{Name: "bob@fabrikam.com", Type: "user"}
+	mock := NewMockClient()
+	mock.ListSubscriptionsFunc = func(_ context.Context) ([]Subscription, error) {
+		return []Subscription{
+			{ID: "s1", Name: "Sub 1", TenantID: "t1", HomeTenantID: "t1", TenantDisplayName: "Tenant 1", IsDefault: true, User: alice},
+			{ID: "s4", Name: "Sub 4", TenantID: "t3", HomeTenantID: "t3", TenantDisplayName: "Tenant 3", User: bob},
+		}, nil
+	}
+	mock.GetAccessTokenFunc = func(_ context.Context, tenantID string) (*AccessToken, error) {
+		if tenantID == "t3" {
+			return nil, fmt.Errorf("%w: %w: AADSTS700082", ErrCommandFailed, ErrAuthRequired)
+		}
+		return &AccessToken{AccessToken: "token-" + tenantID, Tenant: tenantID, ExpiresOn: time.Now().Add(time.Hour).Unix()}, nil
+	}
+	client := armClient(srv, mock)
+
+	subs, err := client.ListSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for i := range subs {
+		ids = append(ids, subs[i].ID)
+	}
+	if strings.Join(ids, ",") != "s1,s4,s3,s2" {
+		t.Fatalf("expected the known subscriptions and then the new ones of each tenant, got %v", ids)
+	}
+
+	s1, s4, s3, s2 := subs[0], subs[1], subs[2], subs[3]
+	if s1.Name != "Sub 1 renamed" || s1.Tags["env"] != "prod" || s1.User != alice || !s1.IsDefault {
+		t.Errorf("expected s1 to keep its user and default with ARM's name and tags, got %+v", s1)
+	}
+	if s4.Name != "Sub 4" || s4.User != bob {
+		t.Errorf("expected s4 of a tenant that needs a login to be kept, got %+v", s4)
+	}
+	if s3.TenantID != "t1" || s3.TenantDisplayName != "Tenant 1" || s3.User != alice || s3.IsDefault {
+		t.Errorf("expected s3 from the next page as the current user's, got %+v", s3)
+	}
+	if s2.TenantID != "t2" || s2.HomeTenantID != "customer" || s2.State != "Disabled" {
+		t.Errorf("expected s2 listed through t2 with its home tenant, got %+v", s2)
+	}
+
+	// Tokens are cached per tenant; only the failed one is obtained again.
+	if _, err := client.ListSubscriptions(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, id := range mock.Calls.GetAccessToken {
+		counts[id]++
+	}
+	if counts[""] != 1 || counts["t1"] != 1 || counts["t2"] != 1 || counts["t3"] != 2 {
+		t.Errorf("expected one token per tenant, got %v", mock.Calls.GetAccessToken)
+	}
+}
+
+func TestARMClient_ListSubscriptions_KeepsKnownWithoutTenants(t *testing.T) {
+	known := []Subscription{{ID: "s1", Name: "Sub 1", TenantID: "t1", IsDefault: true}}
+
+	for name, token := range map[string]string{"failing tenants": "token", "rejected token": "revoked"} {
+		t.Run(name, func(t *testing.T) {
+			srv := armServer(t, map[string]http.HandlerFunc{
+				"/tenants": func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprint(w, `{"error":{"code":"InternalServerError","message":"Something went wrong."}}`)
+				},
+			})
+			mock := NewMockClient()
+			mock.ListSubscriptionsFunc = func(_ context.Context) ([]Subscription, error) {
+				return known, nil
+			}
+			mock.GetAccessTokenFunc = func(_ context.Context, _ string) (*AccessToken, error) {
+				return &AccessToken{AccessToken: token, ExpiresOn: time.Now().Add(time.Hour).Unix()}, nil
+			}
+
+			subs, err := armClient(srv, mock).ListSubscriptions(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(subs) != 1 || subs[0].ID != "s1" {
+				t.Errorf("expected the known subscriptions, got %+v", subs)
+			}
+		})
+	}
+}
+
+func TestARMClient_DefaultHTTPClientTimesOut(t *testing.T) {
+	client := NewARMClient(NewMockClient())
+	if client.httpClient.Timeout <= 0 {
+		t.Error("expected the default HTTP client to have a timeout")
+	}
+}
+
+func TestARMClient_RetriesThrottledAndFailedRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := armServer(t, map[string]http.HandlerFunc{
+		"/tenants": func(w http.ResponseWriter, _ *http.Request) {
+			switch calls.Add(1) {
+			case 1:
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			case 2:
+				w.WriteHeader(http.StatusBadGateway)
+			default:
+				fmt.Fprint(w, `{"value":[{"tenantId":"t1"}]}`)
+			}
+		},
+	})
+
+	tenants, err := armClient(srv, NewMockClient()).ListTenants(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tenants) != 1 || calls.Load() != 3 {
+		t.Errorf("expected success on the third attempt, got %v after %d", tenants, calls.Load())
+	}
+}
+
+func TestARMClient_GivesUpAfterRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := armServer(t, map[string]http.HandlerFunc{
+		"/tenants": func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"code":"ServiceUnavailable","message":"Try again later."}}`)
+		},
+	})
+
+	_, err := armClient(srv, NewMockClient()).ListTenants(context.Background())
+	if !errors.Is(err, ErrRequestFailed) || !strings.Contains(err.Error(), "Try again later.") {
+		t.Errorf("expected the ARM error, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected one try and two retries, got %d", calls.Load())
+	}
+}
+
+func TestARMClient_Unauthorized(t *testing.T) {
+	srv := armServer(t, nil)
+
+	mock := NewMockClient()
+	mock.GetAccessTokenFunc = func(_ context.Context, _ string) (*AccessToken, error) {
+		return &AccessToken{AccessToken: "revoked", ExpiresOn: time.Now().Add(time.Hour).Unix()}, nil
+	}
+	client := armClient(srv, mock)
+
+	_, err := client.ListTenants(context.Background())
+	if !errors.Is(err, ErrAuthRequired) {
+		t.Fatalf("expected ErrAuthRequired, got %v", err)
+	}
+
+	// A rejected token is obtained again.
+	_, _ = client.ListTenants(context.Background())
+	if len(mock.Calls.GetAccessToken) != 2 {
+		t.Errorf("expected a new token after a rejection, got %v", mock.Calls.GetAccessToken)
+	}
+}
+
+func TestARMClient_RejectsForeignNextLink(t *testing.T) {
+	srv := armServer(t, map[string]http.HandlerFunc{
+		"/tenants": func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, `{"value":[],"nextLink":"https://attacker.example/tenants"}`)
+		},
+	})
+
+	if _, err := armClient(srv, NewMockClient()).ListTenants(context.Background()); !errors.Is(err, ErrRequestFailed) {
+		t.Errorf("expected a foreign next page to be refused, got %v", err)
+	}
+}
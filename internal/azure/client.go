@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 )
@@ -15,8 +16,26 @@ var (
 	ErrAzureCLINotInstalled = errors.New("azure CLI is not installed")
 	ErrNotLoggedIn          = errors.New("not logged in to Azure CLI")
 	ErrCommandFailed        = errors.New("azure CLI command failed")
+
+	// ErrAuthRequired is wrapped by command failures caused by a missing,
+	// expired or revoked login, which az login would fix.
+	ErrAuthRequired = errors.New("authentication required")
 )
 
+// authFailures are markers of the Azure CLI's login and token errors.
+var authFailures = []string{
+	"az login",
+	"AADSTS",
+	"interaction_required",
+	"invalid_grant",
+	"refresh token",
+	"InvalidAuthenticationToken",
+	"ExpiredAuthenticationToken",
+}
+
+// subscriptionsAPIVersion is the ARM API version of subscription requests.
+const subscriptionsAPIVersion = "2022-12-01"
+
 // Client defines the interface for Azure CLI operations.
 type Client interface {
 	// CheckCLI verifies that Azure CLI is installed.
@@ -37,8 +56,73 @@ type Client interface {
 	// SetSubscription switches to the specified subscription.
 	SetSubscription(ctx context.Context, subscriptionIDOrName string) error
 
+	// SetSubscriptionUser makes a subscription use the credentials of another
+	// signed-in user.
+	SetSubscriptionUser(ctx context.Context, subscriptionID string, user User) error
+
+	// GetSubscriptionTags returns the ARM tags of a subscription.
+	GetSubscriptionTags(ctx context.Context, subscriptionID string) (map[string]string, error)
+
 	// LoginToTenant logs in to a specific tenant.
-	LoginToTenant(ctx context.Context, tenantID string) error
+	LoginToTenant(ctx context.Context, tenantID string, opts LoginOptions) error
+
+	// Logout signs a user out, or the current user if username is empty,
+	// removing their subscriptions from the profile.
+	Logout(ctx context.Context, username string) error
+
+	// LogoutAll signs every user out and clears the profile.
+	LogoutAll(ctx context.Context) error
+
+	// RemoveSubscriptions removes subscriptions from the profile, without
+	// signing anyone out.
+	RemoveSubscriptions(ctx context.Context, ids []string) error
+
+	// GetAccessToken returns an access token for a tenant, or for the current
+	// tenant if tenantID is empty, refreshing it silently if needed. It fails
+	// with ErrAuthRequired when only an interactive login would help.
+	GetAccessToken(ctx context.Context, tenantID string) (*AccessToken, error)
+
+	// ListAKSClusters returns the AKS clusters in a subscription.
+	ListAKSClusters(ctx context.Context, subscriptionID string) ([]AKSCluster, error)
+
+	// GetAKSCredentials merges a cluster's credentials into the kubeconfig at
+	// kubeconfigPath and makes it the current context.
+	GetAKSCredentials(ctx context.Context, cluster AKSCluster, kubeconfigPath string) error
+}
+
+// LoginOptions configures an interactive login to a tenant.
+type LoginOptions struct {
+	// AllowNoSubscriptions logs in at tenant level, for tenants without
+	// subscriptions.
+	AllowNoSubscriptions bool
+
+	// UseDeviceCode prints a code to enter on another device instead of
+	// opening a browser.
+	UseDeviceCode bool
+
+	// Stdin, Stdout and Stderr attach the login to a terminal, so that the
+	// browser prompt or device code reaches the user. When all are nil the
+	// login runs in the background.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// attached reports whether the login runs on a terminal.
+func (o LoginOptions) attached() bool {
+	return o.Stdin != nil || o.Stdout != nil || o.Stderr != nil
+}
+
+// LoginArgs returns the az arguments of an interactive login to a tenant.
+func LoginArgs(tenantID string, opts LoginOptions) []string {
+	args := []string{"login", "--tenant", tenantID}
+	if opts.AllowNoSubscriptions {
+		args = append(args, "--allow-no-subscriptions")
+	}
+	if opts.UseDeviceCode {
+		args = append(args, "--use-device-code")
+	}
+	return args
 }
 
 // CLIClient implements Client using the Azure CLI.
@@ -47,11 +131,25 @@ type CLIClient struct {
 	azPath string
 }
 
+// CLIOption configures a CLIClient.
+type CLIOption func(*CLIClient)
+
+// WithAzPath runs the az binary at path instead of the one on the PATH.
+func WithAzPath(path string) CLIOption {
+	return func(c *CLIClient) {
+		c.azPath = path
+	}
+}
+
 // NewCLIClient creates a new Azure CLI client.
-func NewCLIClient() *CLIClient {
-	return &CLIClient{
+func NewCLIClient(opts ...CLIOption) *CLIClient {
+	c := &CLIClient{
 		azPath: "az",
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // CheckCLI verifies that Azure CLI is installed.
@@ -127,12 +225,151 @@ func (c *CLIClient) SetSubscription(ctx context.Context, subscriptionIDOrName st
 	return err
 }
 
-// LoginToTenant logs in to a specific tenant.
-func (c *CLIClient) LoginToTenant(ctx context.Context, tenantID string) error {
-	_, err := c.runCommand(ctx, "login", "--tenant", tenantID, "--output", "none")
+// SetSubscriptionUser makes a subscription use the credentials of another
+// signed-in user. The Azure CLI has no command for this, so
+// azureProfile.json is edited directly.
+func (c *CLIClient) SetSubscriptionUser(_ context.Context, subscriptionID string, user User) error {
+	profile, err := c.loadProfile()
+	if err != nil {
+		return err
+	}
+	if err := profile.SetUser(subscriptionID, user); err != nil {
+		return err
+	}
+	return profile.Save()
+}
+
+// GetSubscriptionTags returns the ARM tags of a subscription, read with az
+// rest since no az command returns them. The Azure CLI picks the token of the
+// subscription's tenant from the URL.
+func (c *CLIClient) GetSubscriptionTags(ctx context.Context, subscriptionID string) (map[string]string, error) {
+	url := "/subscriptions/" + subscriptionID + "?api-version=" + subscriptionsAPIVersion
+	output, err := c.runCommand(ctx, "rest", "--method", "get", "--url", url, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var sub struct {
+		Tags map[string]string `json:"tags"`
+	}
+	if err := json.Unmarshal(output, &sub); err != nil {
+		return nil, fmt.Errorf("failed to parse subscription tags: %w", err)
+	}
+
+	return sub.Tags, nil
+}
+
+// LoginToTenant logs in to a specific tenant, on the terminal opts attach
+// it to, if any.
+func (c *CLIClient) LoginToTenant(ctx context.Context, tenantID string, opts LoginOptions) error {
+	args := append(LoginArgs(tenantID, opts), "--output", "none")
+	if !opts.attached() {
+		_, err := c.runCommand(ctx, args...)
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, c.azPath, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = opts.Stdin, opts.Stdout, opts.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCommandFailed, err)
+	}
+	return nil
+}
+
+// Logout signs a user out, or the current user if username is empty.
+func (c *CLIClient) Logout(ctx context.Context, username string) error {
+	args := []string{"logout"}
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	_, err := c.runCommand(ctx, args...)
+	return err
+}
+
+// LogoutAll signs every user out and clears the profile.
+func (c *CLIClient) LogoutAll(ctx context.Context) error {
+	_, err := c.runCommand(ctx, "account", "clear")
+	return err
+}
+
+// RemoveSubscriptions removes subscriptions from the profile. The Azure CLI
+// has no command for this, so azureProfile.json is edited directly.
+func (c *CLIClient) RemoveSubscriptions(_ context.Context, ids []string) error {
+	profile, err := c.loadProfile()
+	if err != nil {
+		return err
+	}
+	if profile.Remove(ids...) == 0 {
+		return nil
+	}
+	return profile.Save()
+}
+
+// GetAccessToken returns an access token for a tenant, or for the current tenant.
+func (c *CLIClient) GetAccessToken(ctx context.Context, tenantID string) (*AccessToken, error) {
+	args := []string{"account", "get-access-token", "--output", "json"}
+	if tenantID != "" {
+		args = append(args, "--tenant", tenantID)
+	}
+
+	output, err := c.runCommand(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var token AccessToken
+	if err := json.Unmarshal(output, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse access token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// ListAKSClusters returns the AKS clusters in a subscription.
+func (c *CLIClient) ListAKSClusters(ctx context.Context, subscriptionID string) ([]AKSCluster, error) {
+	output, err := c.runCommand(ctx, "aks", "list", "--subscription", subscriptionID, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []AKSCluster
+	if err := json.Unmarshal(output, &clusters); err != nil {
+		return nil, fmt.Errorf("failed to parse AKS clusters: %w", err)
+	}
+
+	return clusters, nil
+}
+
+// GetAKSCredentials merges a cluster's credentials into the kubeconfig at
+// kubeconfigPath and makes it the current context.
+func (c *CLIClient) GetAKSCredentials(ctx context.Context, cluster AKSCluster, kubeconfigPath string) error {
+	args := []string{
+		"aks", "get-credentials",
+		"--resource-group", cluster.ResourceGroup,
+		"--name", cluster.Name,
+		"--overwrite-existing",
+		"--output", "none",
+	}
+	if sub := cluster.SubscriptionID(); sub != "" {
+		args = append(args, "--subscription", sub)
+	}
+	if kubeconfigPath != "" {
+		args = append(args, "--file", kubeconfigPath)
+	}
+
+	_, err := c.runCommand(ctx, args...)
 	return err
 }
 
+// loadProfile loads the profile of the Azure CLI config directory.
+func (c *CLIClient) loadProfile() (*Profile, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return LoadProfile(dir)
+}
+
 // runCommand executes an Azure CLI command and returns the output.
 func (c *CLIClient) runCommand(ctx context.Context, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, c.azPath, args...)
@@ -146,8 +383,21 @@ func (c *CLIClient) runCommand(ctx context.Context, args ...string) ([]byte, err
 		if errMsg == "" {
 			errMsg = err.Error()
 		}
+		if isAuthFailure(errMsg) {
+			return nil, fmt.Errorf("%w: %w: %s", ErrCommandFailed, ErrAuthRequired, strings.TrimSpace(errMsg))
+		}
 		return nil, fmt.Errorf("%w: %s", ErrCommandFailed, strings.TrimSpace(errMsg))
 	}
 
 	return stdout.Bytes(), nil
 }
+
+// isAuthFailure reports whether Azure CLI error output asks for a new login.
+func isAuthFailure(output string) bool {
+	for _, marker := range authFailures {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,11 @@
 package azure
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -89,7 +93,7 @@ func TestMockClient_LoginToTenant(t *testing.T) {
 	client := NewMockClient()
 	ctx := context.Background()
 
-	err := client.LoginToTenant(ctx, "test-tenant-id")
+	err := client.LoginToTenant(ctx, "test-tenant-id", LoginOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -178,3 +182,264 @@ func TestMockClient_CustomBehavior(t *testing.T) {
 		t.Errorf("expected ErrAzureCLINotInstalled, got %v", err)
 	}
 }
+
+func TestAKSCluster_SubscriptionID(t *testing.T) {
+	cluster := AKSCluster{
+		ID: "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks",
+	}
+
+	if cluster.SubscriptionID() != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("unexpected subscription ID '%s'", cluster.SubscriptionID())
+	}
+
+	if (AKSCluster{}).SubscriptionID() != "" {
+		t.Error("expected empty subscription ID for empty resource ID")
+	}
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	mock := NewMockClient()
+	client := NewReadOnlyClient(mock)
+	ctx := context.Background()
+
+	if _, err := client.ListSubscriptions(ctx); err != nil {
+		t.Fatalf("expected reads to pass through, got %v", err)
+	}
+
+	if mock.Calls.ListSubscriptions != 1 {
+		t.Errorf("expected 1 ListSubscriptions call, got %d", mock.Calls.ListSubscriptions)
+	}
+
+	if err := client.SetSubscription(ctx, "id"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from SetSubscription, got %v", err)
+	}
+
+	if err := client.LoginToTenant(ctx, "tid", LoginOptions{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from LoginToTenant, got %v", err)
+	}
+
+	if err := client.Logout(ctx, ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from Logout, got %v", err)
+	}
+
+	if err := client.RemoveSubscriptions(ctx, []string{"id"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from RemoveSubscriptions, got %v", err)
+	}
+
+	if err := client.GetAKSCredentials(ctx, AKSCluster{}, ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from GetAKSCredentials, got %v", err)
+	}
+
+	if len(mock.Calls.SetSubscription) != 0 || len(mock.Calls.LoginToTenant) != 0 || len(mock.Calls.GetAKSCredentials) != 0 ||
+		len(mock.Calls.Logout) != 0 || len(mock.Calls.RemoveSubscriptions) != 0 {
+		t.Error("expected mutating calls not to reach the wrapped client")
+	}
+
+	if NewReadOnlyClient(client) != client {
+		t.Error("expected a read-only client not to be wrapped again")
+	}
+}
+
+func TestIsAuthFailure(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"ERROR: AADSTS700082: The refresh token has expired due to inactivity.", true},
+		{"ERROR: Please run 'az login' to setup account.", true},
+		{"ERROR: (InvalidAuthenticationToken) The access token is invalid.", true},
+		{"ERROR: The subscription 'x' doesn't exist in cloud 'AzureCloud'.", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAuthFailure(tt.output); got != tt.want {
+			t.Errorf("isAuthFailure(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestAccessToken_Expiry(t *testing.T) {
+	token := AccessToken{ExpiresOn: 1700000000}
+	if got := token.Expiry().Unix(); got != 1700000000 {
+		t.Errorf("expected expiry 1700000000, got %d", got)
+	}
+}
+
+func TestCLIClient_GetCurrentAccount(t *testing.T) {
+	client, calls := fakeAzClient(t, azRule{Args: "^account show --output json$", StdoutFile: "account-show.json"})
+
+	account, err := client.GetCurrentAccount(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.Name != "Contoso Production" || account.TenantDisplayName != "Contoso" || account.User.Name != "alice@contoso.com" {
+		t.Errorf("unexpected account %+v", account)
+	}
+	if got := calls(); len(got) != 1 {
+		t.Errorf("expected one az call, got %v", got)
+	}
+}
+
+func TestCLIClient_ListSubscriptionsAndTenants(t *testing.T) {
+	client, _ := fakeAzClient(t,
+		azRule{Args: "^account list ", StdoutFile: "account-list.json"},
+		azRule{Args: "^account tenant list ", StdoutFile: "tenant-list.json"},
+	)
+	ctx := context.Background()
+
+	subs, err := client.ListSubscriptions(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(subs) != 2 || !subs[0].IsDefault || subs[1].State != "Disabled" || len(subs[1].ManagedByTenants) != 1 {
+		t.Errorf("unexpected subscriptions %+v", subs)
+	}
+
+	tenants, err := client.ListTenants(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tenants) != 2 || tenants[1].DefaultDomain != "fabrikam.onmicrosoft.com" || len(tenants[0].Domains) != 2 {
+		t.Errorf("unexpected tenants %+v", tenants)
+	}
+}
+
+func TestCLIClient_Arguments(t *testing.T) {
+	client, calls := fakeAzClient(t,
+		azRule{Args: "^account get-access-token ", StdoutFile: "get-access-token.json"},
+		azRule{Args: "^rest ", StdoutFile: "rest-subscription.json"},
+		azRule{Args: "^aks list ", StdoutFile: "aks-list.json"},
+		azRule{Args: "^(account set|login|logout|aks get-credentials) "},
+	)
+	ctx := context.Background()
+
+	token, err := client.GetAccessToken(ctx, "22222222-2222-2222-2222-222222222222")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Tenant != "22222222-2222-2222-2222-222222222222" || token.Expiry().Unix() != 1748782800 {
+		t.Errorf("unexpected token %+v", token)
+	}
+
+	tags, err := client.GetSubscriptionTags(ctx, "aaaaaaaa-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tags["env"] != "prod" || tags["owner"] != "platform-team" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	clusters, err := client.ListAKSClusters(ctx, "aaaaaaaa-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].SubscriptionID() != "aaaaaaaa-0000-0000-0000-000000000001" {
+		t.Errorf("unexpected clusters %+v", clusters)
+	}
+
+	for _, err := range []error{
+		client.SetSubscription(ctx, "Contoso Production"),
+		client.LoginToTenant(ctx, "tenant-b", LoginOptions{AllowNoSubscriptions: true}),
+		client.Logout(ctx, "bob@contoso.com"),
+		client.GetAKSCredentials(ctx, clusters[0], "/tmp/kubeconfig"),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := []string{
+		"account get-access-token --output json --tenant 22222222-2222-2222-2222-222222222222",
+		"rest --method get --url /subscriptions/aaaaaaaa-0000-0000-0000-000000000001?api-version=2022-12-01 --output json",
+		"aks list --subscription aaaaaaaa-0000-0000-0000-000000000001 --output json",
+		"account set --subscription Contoso Production",
+		"login --tenant tenant-b --allow-no-subscriptions --output none",
+		"logout --username bob@contoso.com",
+		"aks get-credentials --resource-group prod-rg --name prod-aks --overwrite-existing --output none --subscription aaaaaaaa-0000-0000-0000-000000000001 --file /tmp/kubeconfig",
+	}
+	got := calls()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected az calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCLIClient_LoginToTenant_Attached(t *testing.T) {
+	client, calls := fakeAzClient(t,
+		azRule{Args: "^login --tenant tenant-a ", Stdout: "To sign in, use a web browser to open the page https://microsoft.com/devicelogin\n"},
+		azRule{Args: "^login --tenant tenant-b ", Stderr: "AADSTS50076: MFA required\n", Exit: 1},
+	)
+	ctx := context.Background()
+
+	var stdout, stderr bytes.Buffer
+	opts := LoginOptions{UseDeviceCode: true, Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+	if err := client.LoginToTenant(ctx, "tenant-a", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "devicelogin") {
+		t.Errorf("expected the device code prompt on the terminal, got %q", stdout.String())
+	}
+
+	err := client.LoginToTenant(ctx, "tenant-b", opts)
+	if !errors.Is(err, ErrCommandFailed) || !strings.Contains(stderr.String(), "MFA required") {
+		t.Errorf("expected a failure with its output on the terminal, got %v and %q", err, stderr.String())
+	}
+	if got := calls(); len(got) != 2 || got[0] != "login --tenant tenant-a --use-device-code --output none" {
+		t.Errorf("unexpected az calls %q", got)
+	}
+}
+
+func TestCLIClient_Errors(t *testing.T) {
+	client, _ := fakeAzClient(t,
+		azRule{Args: "^account get-access-token ", StderrFile: "token-expired.txt", Exit: 1},
+		azRule{Args: "^account set ", StderrFile: "subscription-not-found.txt", Exit: 1},
+		azRule{Args: "^account list ", Stdout: "[{not json", Exit: 0},
+		azRule{Args: "^account tenant list ", Exit: 3},
+	)
+	ctx := context.Background()
+
+	_, err := client.GetAccessToken(ctx, "")
+	if !errors.Is(err, ErrCommandFailed) || !errors.Is(err, ErrAuthRequired) {
+		t.Errorf("expected an auth failure, got %v", err)
+	}
+
+	err = client.SetSubscription(ctx, "missing")
+	if !errors.Is(err, ErrCommandFailed) || errors.Is(err, ErrAuthRequired) {
+		t.Errorf("expected a plain command failure, got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), "doesn't exist in cloud 'AzureCloud'.") {
+		t.Errorf("expected the trimmed az error, got %q", err)
+	}
+
+	if _, err := client.ListSubscriptions(ctx); err == nil || !strings.Contains(err.Error(), "failed to parse subscriptions") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+
+	// Without stderr, the exit status is reported.
+	if _, err := client.ListTenants(ctx); !errors.Is(err, ErrCommandFailed) || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("expected the exit status, got %v", err)
+	}
+}
+
+func TestCLIClient_CheckLogin(t *testing.T) {
+	client, _ := fakeAzClient(t, azRule{Args: "^account show ", StderrFile: "not-logged-in.txt", Exit: 1})
+	if err := client.CheckLogin(context.Background()); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expected ErrNotLoggedIn, got %v", err)
+	}
+
+	client, _ = fakeAzClient(t, azRule{Args: "^account show ", StdoutFile: "account-show.json"})
+	if err := client.CheckLogin(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCLIClient_CheckCLI(t *testing.T) {
+	client, _ := fakeAzClient(t)
+	if err := client.CheckCLI(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	missing := NewCLIClient(WithAzPath(filepath.Join(t.TempDir(), "az")))
+	if err := missing.CheckCLI(context.Background()); !errors.Is(err, ErrAzureCLINotInstalled) {
+		t.Errorf("expected ErrAzureCLINotInstalled, got %v", err)
+	}
+}
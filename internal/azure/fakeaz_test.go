@@ -0,0 +1,147 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// envFakeAz makes the test binary act as az, replaying the rules in the
+// JSON file it names.
+const envFakeAz = "AZSWITCH_FAKE_AZ"
+
+// azRule replays one az invocation. Fixture files are read from testdata/az.
+type azRule struct {
+	// Args is a regular expression matched against the space-joined arguments.
+	Args string `json:"args"`
+
+	// Stdout and StdoutFile are written to stdout.
+	Stdout     string `json:"stdout,omitempty"`
+	StdoutFile string `json:"stdout_file,omitempty"`
+
+	// Stderr and StderrFile are written to stderr.
+	Stderr     string `json:"stderr,omitempty"`
+	StderrFile string `json:"stderr_file,omitempty"`
+
+	// Exit is the exit code.
+	Exit int `json:"exit,omitempty"`
+}
+
+// fakeAzScript is what the fake az reads: its rules, and where it logs the
+// arguments of each invocation.
+type fakeAzScript struct {
+	Rules []azRule `json:"rules"`
+	Log   string   `json:"log"`
+}
+
+// TestMain runs the test binary as the fake az when asked to.
+func TestMain(m *testing.M) {
+	if path := os.Getenv(envFakeAz); path != "" {
+		os.Exit(fakeAz(path, os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeAz replays the first rule of the script at path that matches args.
+func fakeAz(path string, args []string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fake az: %v\n", err)
+		return 2
+	}
+	var script fakeAzScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		fmt.Fprintf(os.Stderr, "fake az: %v\n", err)
+		return 2
+	}
+
+	line := strings.Join(args, " ")
+	if log, err := os.OpenFile(script.Log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err == nil {
+		fmt.Fprintln(log, line)
+		log.Close()
+	}
+
+	for _, rule := range script.Rules {
+		if !regexp.MustCompile(rule.Args).MatchString(line) {
+			continue
+		}
+		if err := replay(os.Stdout, rule.Stdout, rule.StdoutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "fake az: %v\n", err)
+			return 2
+		}
+		if err := replay(os.Stderr, rule.Stderr, rule.StderrFile); err != nil {
+			fmt.Fprintf(os.Stderr, "fake az: %v\n", err)
+			return 2
+		}
+		return rule.Exit
+	}
+
+	fmt.Fprintf(os.Stderr, "fake az: no rule for %q\n", line)
+	return 2
+}
+
+// replay writes the inline text and then the fixture file, if any, to f.
+func replay(f *os.File, text, file string) error {
+	if _, err := f.WriteString(text); err != nil {
+		return err
+	}
+	if file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// fakeAzClient returns a CLIClient running the test binary as az with the
+// given rules, and a function returning the arguments of each invocation so
+// far.
+func fakeAzClient(t *testing.T, rules ...azRule) (*CLIClient, func() []string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	fixtures, err := filepath.Abs(filepath.Join("testdata", "az"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rules {
+		if rules[i].StdoutFile != "" {
+			rules[i].StdoutFile = filepath.Join(fixtures, rules[i].StdoutFile)
+		}
+		if rules[i].StderrFile != "" {
+			rules[i].StderrFile = filepath.Join(fixtures, rules[i].StderrFile)
+		}
+	}
+
+	script := fakeAzScript{Rules: rules, Log: filepath.Join(dir, "calls.log")}
+	data, err := json.Marshal(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "script.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envFakeAz, path)
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := func() []string {
+		data, err := os.ReadFile(script.Log)
+		if err != nil {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	return NewCLIClient(WithAzPath(exe)), calls
+}
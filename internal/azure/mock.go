@@ -2,9 +2,12 @@ package azure
 
 import (
 	"context"
+	"sync"
+	"time"
 )
 
-// MockClient is a mock implementation of the Azure Client interface for testing.
+// MockClient is a mock implementation of the Azure Client interface for
+// testing. Calls are recorded safely from several goroutines.
 type MockClient struct {
 	// CheckCLIFunc is called when CheckCLI is invoked.
 	CheckCLIFunc func(ctx context.Context) error
@@ -24,19 +27,53 @@ type MockClient struct {
 	// SetSubscriptionFunc is called when SetSubscription is invoked.
 	SetSubscriptionFunc func(ctx context.Context, subscriptionIDOrName string) error
 
+	// SetSubscriptionUserFunc is called when SetSubscriptionUser is invoked.
+	SetSubscriptionUserFunc func(ctx context.Context, subscriptionID string, user User) error
+
+	// GetSubscriptionTagsFunc is called when GetSubscriptionTags is invoked.
+	GetSubscriptionTagsFunc func(ctx context.Context, subscriptionID string) (map[string]string, error)
+
 	// LoginToTenantFunc is called when LoginToTenant is invoked.
-	LoginToTenantFunc func(ctx context.Context, tenantID string) error
+	LoginToTenantFunc func(ctx context.Context, tenantID string, opts LoginOptions) error
+
+	// LogoutFunc is called when Logout is invoked.
+	LogoutFunc func(ctx context.Context, username string) error
+
+	// LogoutAllFunc is called when LogoutAll is invoked.
+	LogoutAllFunc func(ctx context.Context) error
+
+	// RemoveSubscriptionsFunc is called when RemoveSubscriptions is invoked.
+	RemoveSubscriptionsFunc func(ctx context.Context, ids []string) error
+
+	// GetAccessTokenFunc is called when GetAccessToken is invoked.
+	GetAccessTokenFunc func(ctx context.Context, tenantID string) (*AccessToken, error)
+
+	// ListAKSClustersFunc is called when ListAKSClusters is invoked.
+	ListAKSClustersFunc func(ctx context.Context, subscriptionID string) ([]AKSCluster, error)
+
+	// GetAKSCredentialsFunc is called when GetAKSCredentials is invoked.
+	GetAKSCredentialsFunc func(ctx context.Context, cluster AKSCluster, kubeconfigPath string) error
 
 	// Calls tracks function call history.
 	Calls struct {
-		CheckCLI          int
-		CheckLogin        int
-		GetCurrentAccount int
-		ListSubscriptions int
-		ListTenants       int
-		SetSubscription   []string
-		LoginToTenant     []string
+		CheckCLI            int
+		CheckLogin          int
+		GetCurrentAccount   int
+		ListSubscriptions   int
+		ListTenants         int
+		SetSubscription     []string
+		SetSubscriptionUser []string
+		GetSubscriptionTags []string
+		LoginToTenant       []string
+		Logout              []string
+		LogoutAll           int
+		RemoveSubscriptions []string
+		GetAccessToken      []string
+		ListAKSClusters     []string
+		GetAKSCredentials   []string
 	}
+
+	mu sync.Mutex
 }
 
 // NewMockClient creates a new mock client with default implementations.
@@ -99,7 +136,43 @@ func NewMockClient() *MockClient {
 		SetSubscriptionFunc: func(_ context.Context, _ string) error {
 			return nil
 		},
-		LoginToTenantFunc: func(_ context.Context, _ string) error {
+		SetSubscriptionUserFunc: func(_ context.Context, _ string, _ User) error {
+			return nil
+		},
+		GetSubscriptionTagsFunc: func(_ context.Context, _ string) (map[string]string, error) {
+			return nil, nil
+		},
+		LoginToTenantFunc: func(_ context.Context, _ string, _ LoginOptions) error {
+			return nil
+		},
+		LogoutFunc: func(_ context.Context, _ string) error {
+			return nil
+		},
+		LogoutAllFunc: func(_ context.Context) error {
+			return nil
+		},
+		RemoveSubscriptionsFunc: func(_ context.Context, _ []string) error {
+			return nil
+		},
+		GetAccessTokenFunc: func(_ context.Context, tenantID string) (*AccessToken, error) {
+			return &AccessToken{
+				AccessToken: "token",
+				ExpiresOn:   time.Now().Add(time.Hour).Unix(),
+				Tenant:      tenantID,
+				TokenType:   "Bearer",
+			}, nil
+		},
+		ListAKSClustersFunc: func(_ context.Context, subscriptionID string) ([]AKSCluster, error) {
+			return []AKSCluster{
+				{
+					ID:            "/subscriptions/" + subscriptionID + "/resourceGroups/test-rg/providers/Microsoft.ContainerService/managedClusters/test-aks",
+					Name:          "test-aks",
+					ResourceGroup: "test-rg",
+					Location:      "westeurope",
+				},
+			}, nil
+		},
+		GetAKSCredentialsFunc: func(_ context.Context, _ AKSCluster, _ string) error {
 			return nil
 		},
 	}
@@ -107,44 +180,122 @@ func NewMockClient() *MockClient {
 
 // CheckCLI implements Client.
 func (m *MockClient) CheckCLI(ctx context.Context) error {
+	m.mu.Lock()
 	m.Calls.CheckCLI++
+	m.mu.Unlock()
 	return m.CheckCLIFunc(ctx)
 }
 
 // CheckLogin implements Client.
 func (m *MockClient) CheckLogin(ctx context.Context) error {
+	m.mu.Lock()
 	m.Calls.CheckLogin++
+	m.mu.Unlock()
 	return m.CheckLoginFunc(ctx)
 }
 
 // GetCurrentAccount implements Client.
 func (m *MockClient) GetCurrentAccount(ctx context.Context) (*Account, error) {
+	m.mu.Lock()
 	m.Calls.GetCurrentAccount++
+	m.mu.Unlock()
 	return m.GetCurrentAccountFunc(ctx)
 }
 
 // ListSubscriptions implements Client.
 func (m *MockClient) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	m.mu.Lock()
 	m.Calls.ListSubscriptions++
+	m.mu.Unlock()
 	return m.ListSubscriptionsFunc(ctx)
 }
 
 // ListTenants implements Client.
 func (m *MockClient) ListTenants(ctx context.Context) ([]Tenant, error) {
+	m.mu.Lock()
 	m.Calls.ListTenants++
+	m.mu.Unlock()
 	return m.ListTenantsFunc(ctx)
 }
 
 // SetSubscription implements Client.
 func (m *MockClient) SetSubscription(ctx context.Context, subscriptionIDOrName string) error {
+	m.mu.Lock()
 	m.Calls.SetSubscription = append(m.Calls.SetSubscription, subscriptionIDOrName)
+	m.mu.Unlock()
 	return m.SetSubscriptionFunc(ctx, subscriptionIDOrName)
 }
 
+// SetSubscriptionUser implements Client. Calls are recorded as "id=user".
+func (m *MockClient) SetSubscriptionUser(ctx context.Context, subscriptionID string, user User) error {
+	m.mu.Lock()
+	m.Calls.SetSubscriptionUser = append(m.Calls.SetSubscriptionUser, subscriptionID+"="+user.Name)
+	m.mu.Unlock()
+	return m.SetSubscriptionUserFunc(ctx, subscriptionID, user)
+}
+
+// GetSubscriptionTags implements Client.
+func (m *MockClient) GetSubscriptionTags(ctx context.Context, subscriptionID string) (map[string]string, error) {
+	m.mu.Lock()
+	m.Calls.GetSubscriptionTags = append(m.Calls.GetSubscriptionTags, subscriptionID)
+	m.mu.Unlock()
+	return m.GetSubscriptionTagsFunc(ctx, subscriptionID)
+}
+
 // LoginToTenant implements Client.
-func (m *MockClient) LoginToTenant(ctx context.Context, tenantID string) error {
+func (m *MockClient) LoginToTenant(ctx context.Context, tenantID string, opts LoginOptions) error {
+	m.mu.Lock()
 	m.Calls.LoginToTenant = append(m.Calls.LoginToTenant, tenantID)
-	return m.LoginToTenantFunc(ctx, tenantID)
+	m.mu.Unlock()
+	return m.LoginToTenantFunc(ctx, tenantID, opts)
+}
+
+// Logout implements Client.
+func (m *MockClient) Logout(ctx context.Context, username string) error {
+	m.mu.Lock()
+	m.Calls.Logout = append(m.Calls.Logout, username)
+	m.mu.Unlock()
+	return m.LogoutFunc(ctx, username)
+}
+
+// LogoutAll implements Client.
+func (m *MockClient) LogoutAll(ctx context.Context) error {
+	m.mu.Lock()
+	m.Calls.LogoutAll++
+	m.mu.Unlock()
+	return m.LogoutAllFunc(ctx)
+}
+
+// RemoveSubscriptions implements Client.
+func (m *MockClient) RemoveSubscriptions(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	m.Calls.RemoveSubscriptions = append(m.Calls.RemoveSubscriptions, ids...)
+	m.mu.Unlock()
+	return m.RemoveSubscriptionsFunc(ctx, ids)
+}
+
+// GetAccessToken implements Client.
+func (m *MockClient) GetAccessToken(ctx context.Context, tenantID string) (*AccessToken, error) {
+	m.mu.Lock()
+	m.Calls.GetAccessToken = append(m.Calls.GetAccessToken, tenantID)
+	m.mu.Unlock()
+	return m.GetAccessTokenFunc(ctx, tenantID)
+}
+
+// ListAKSClusters implements Client.
+func (m *MockClient) ListAKSClusters(ctx context.Context, subscriptionID string) ([]AKSCluster, error) {
+	m.mu.Lock()
+	m.Calls.ListAKSClusters = append(m.Calls.ListAKSClusters, subscriptionID)
+	m.mu.Unlock()
+	return m.ListAKSClustersFunc(ctx, subscriptionID)
+}
+
+// GetAKSCredentials implements Client.
+func (m *MockClient) GetAKSCredentials(ctx context.Context, cluster AKSCluster, kubeconfigPath string) error {
+	m.mu.Lock()
+	m.Calls.GetAKSCredentials = append(m.Calls.GetAKSCredentials, cluster.Name)
+	m.mu.Unlock()
+	return m.GetAKSCredentialsFunc(ctx, cluster, kubeconfigPath)
 }
 
 // Ensure MockClient implements Client.
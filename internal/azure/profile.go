@@ -0,0 +1,165 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// EnvConfigDir is the environment variable the Azure CLI reads its config directory from.
+const EnvConfigDir = "AZURE_CONFIG_DIR"
+
+// ProfileFile is the name of the Azure CLI profile within the config directory.
+const ProfileFile = "azureProfile.json"
+
+// ErrSubscriptionNotInProfile is returned when a subscription is not in the Azure CLI profile.
+var ErrSubscriptionNotInProfile = errors.New("subscription not found in Azure CLI profile")
+
+// utf8BOM is written by the Azure CLI at the start of azureProfile.json.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ConfigDir returns the Azure CLI config directory, honoring AZURE_CONFIG_DIR.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(EnvConfigDir); dir != "" {
+		return dir, nil
+	}
+	return DefaultConfigDir()
+}
+
+// DefaultConfigDir returns the Azure CLI config directory used when
+// AZURE_CONFIG_DIR is unset.
+func DefaultConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".azure"), nil
+}
+
+// Profile is the Azure CLI azureProfile.json file. Fields azswitch does not
+// use are preserved when the profile is saved.
+type Profile struct {
+	path          string
+	fields        map[string]json.RawMessage
+	subscriptions []map[string]any
+}
+
+// LoadProfile reads azureProfile.json from an Azure CLI config directory.
+func LoadProfile(dir string) (*Profile, error) {
+	path := filepath.Join(dir, ProfileFile)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Azure CLI profile: %w", err)
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	p := &Profile{path: path}
+	if err := json.Unmarshal(data, &p.fields); err != nil {
+		return nil, fmt.Errorf("failed to parse Azure CLI profile: %w", err)
+	}
+	if raw, ok := p.fields["subscriptions"]; ok {
+		if err := json.Unmarshal(raw, &p.subscriptions); err != nil {
+			return nil, fmt.Errorf("failed to parse Azure CLI profile subscriptions: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// SubscriptionIDs returns the IDs of the subscriptions in the profile.
+func (p *Profile) SubscriptionIDs() []string {
+	ids := make([]string, 0, len(p.subscriptions))
+	for _, sub := range p.subscriptions {
+		if id, ok := sub["id"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SetDefault marks the subscription with the given ID as the default.
+func (p *Profile) SetDefault(subscriptionID string) error {
+	found := false
+	for _, sub := range p.subscriptions {
+		id, _ := sub["id"].(string)
+		isDefault := strings.EqualFold(id, subscriptionID)
+		sub["isDefault"] = isDefault
+		found = found || isDefault
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotInProfile, subscriptionID)
+	}
+	return nil
+}
+
+// SetUser sets the user of the subscription with the given ID.
+func (p *Profile) SetUser(subscriptionID string, user User) error {
+	for _, sub := range p.subscriptions {
+		if id, _ := sub["id"].(string); strings.EqualFold(id, subscriptionID) {
+			sub["user"] = map[string]any{"name": user.Name, "type": user.Type}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSubscriptionNotInProfile, subscriptionID)
+}
+
+// Remove removes the subscriptions with the given IDs and returns how many
+// were removed. If the default subscription is removed, the first remaining
+// one becomes the default, so that the Azure CLI keeps working.
+func (p *Profile) Remove(ids ...string) int {
+	hadDefault := p.DefaultSubscriptionID() != ""
+
+	kept := p.subscriptions[:0]
+	for _, sub := range p.subscriptions {
+		id, _ := sub["id"].(string)
+		if !slices.ContainsFunc(ids, func(remove string) bool { return strings.EqualFold(id, remove) }) {
+			kept = append(kept, sub)
+		}
+	}
+	removed := len(p.subscriptions) - len(kept)
+	p.subscriptions = kept
+
+	if hadDefault && p.DefaultSubscriptionID() == "" && len(kept) > 0 {
+		kept[0]["isDefault"] = true
+	}
+	return removed
+}
+
+// Save writes the profile back to its config directory.
+func (p *Profile) Save() error {
+	if p.subscriptions != nil {
+		subs, err := json.Marshal(p.subscriptions)
+		if err != nil {
+			return fmt.Errorf("failed to encode Azure CLI profile: %w", err)
+		}
+		p.fields["subscriptions"] = subs
+	}
+
+	data, err := json.Marshal(p.fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode Azure CLI profile: %w", err)
+	}
+
+	if err := os.WriteFile(p.path, append(utf8BOM, data...), 0o600); err != nil {
+		return fmt.Errorf("failed to write Azure CLI profile: %w", err)
+	}
+	return nil
+}
+
+// DefaultSubscriptionID returns the ID of the default subscription in the profile.
+func (p *Profile) DefaultSubscriptionID() string {
+	for _, sub := range p.subscriptions {
+		if isDefault, _ := sub["isDefault"].(bool); isDefault {
+			id, _ := sub["id"].(string)
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package azure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProfile_Remove(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"installationId": "x", "subscriptions": [
+		{"id": "sub-1", "isDefault": true, "tenantId": "t1"},
+		{"id": "sub-2", "isDefault": false, "tenantId": "t1"},
+		{"id": "sub-3", "isDefault": false, "tenantId": "t2"}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, ProfileFile), append(utf8BOM, data...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadProfile(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := profile.Remove("SUB-1", "sub-2", "missing"); n != 2 {
+		t.Errorf("expected 2 subscriptions removed, got %d", n)
+	}
+	if err := profile.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := LoadProfile(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(saved.SubscriptionIDs(), []string{"sub-3"}) {
+		t.Errorf("unexpected subscriptions %v", saved.SubscriptionIDs())
+	}
+	// The removed default is replaced, and other fields are kept.
+	if saved.DefaultSubscriptionID() != "sub-3" {
+		t.Errorf("expected sub-3 to become the default, got '%s'", saved.DefaultSubscriptionID())
+	}
+	if _, ok := saved.fields["installationId"]; !ok {
+		t.Error("expected unknown fields to be preserved")
+	}
+}
+
+func TestProfile_SetUser(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"subscriptions": [{"id": "sub-1", "user": {"name": "me@contoso.com", "type": "user"}}]}`
+	if err := os.WriteFile(filepath.Join(dir, ProfileFile), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadProfile(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := profile.SetUser("sub-1", User{Name: "admin@contoso.com", Type: "user"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := profile.SetUser("missing", User{}); !errors.Is(err, ErrSubscriptionNotInProfile) {
+		t.Errorf("expected ErrSubscriptionNotInProfile, got %v", err)
+	}
+	if err := profile.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, ProfileFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), `"name":"admin@contoso.com"`) {
+		t.Errorf("expected the new user to be saved, got %s", saved)
+	}
+}
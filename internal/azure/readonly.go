@@ -0,0 +1,102 @@
+package azure
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrReadOnly is returned by ReadOnlyClient for operations that change Azure CLI state.
+var ErrReadOnly = errors.New("azswitch is in read-only mode")
+
+// ReadOnlyClient wraps a Client and rejects every call that would change
+// Azure CLI state, such as switching subscriptions or logging in.
+type ReadOnlyClient struct {
+	client Client
+}
+
+// NewReadOnlyClient wraps client so that mutating calls fail with ErrReadOnly.
+// A client that is already read-only is returned as is.
+func NewReadOnlyClient(client Client) *ReadOnlyClient {
+	if ro, ok := client.(*ReadOnlyClient); ok {
+		return ro
+	}
+	return &ReadOnlyClient{client: client}
+}
+
+// CheckCLI implements Client.
+func (c *ReadOnlyClient) CheckCLI(ctx context.Context) error {
+	return c.client.CheckCLI(ctx)
+}
+
+// CheckLogin implements Client.
+func (c *ReadOnlyClient) CheckLogin(ctx context.Context) error {
+	return c.client.CheckLogin(ctx)
+}
+
+// GetCurrentAccount implements Client.
+func (c *ReadOnlyClient) GetCurrentAccount(ctx context.Context) (*Account, error) {
+	return c.client.GetCurrentAccount(ctx)
+}
+
+// ListSubscriptions implements Client.
+func (c *ReadOnlyClient) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return c.client.ListSubscriptions(ctx)
+}
+
+// ListTenants implements Client.
+func (c *ReadOnlyClient) ListTenants(ctx context.Context) ([]Tenant, error) {
+	return c.client.ListTenants(ctx)
+}
+
+// SetSubscription always fails with ErrReadOnly.
+func (c *ReadOnlyClient) SetSubscription(_ context.Context, _ string) error {
+	return ErrReadOnly
+}
+
+// SetSubscriptionUser always fails with ErrReadOnly.
+func (c *ReadOnlyClient) SetSubscriptionUser(_ context.Context, _ string, _ User) error {
+	return ErrReadOnly
+}
+
+// GetSubscriptionTags implements Client.
+func (c *ReadOnlyClient) GetSubscriptionTags(ctx context.Context, subscriptionID string) (map[string]string, error) {
+	return c.client.GetSubscriptionTags(ctx, subscriptionID)
+}
+
+// LoginToTenant always fails with ErrReadOnly.
+func (c *ReadOnlyClient) LoginToTenant(_ context.Context, _ string, _ LoginOptions) error {
+	return ErrReadOnly
+}
+
+// Logout always fails with ErrReadOnly.
+func (c *ReadOnlyClient) Logout(_ context.Context, _ string) error {
+	return ErrReadOnly
+}
+
+// LogoutAll always fails with ErrReadOnly.
+func (c *ReadOnlyClient) LogoutAll(_ context.Context) error {
+	return ErrReadOnly
+}
+
+// RemoveSubscriptions always fails with ErrReadOnly.
+func (c *ReadOnlyClient) RemoveSubscriptions(_ context.Context, _ []string) error {
+	return ErrReadOnly
+}
+
+// GetAccessToken implements Client. Refreshing a token changes no switch state.
+func (c *ReadOnlyClient) GetAccessToken(ctx context.Context, tenantID string) (*AccessToken, error) {
+	return c.client.GetAccessToken(ctx, tenantID)
+}
+
+// ListAKSClusters implements Client.
+func (c *ReadOnlyClient) ListAKSClusters(ctx context.Context, subscriptionID string) ([]AKSCluster, error) {
+	return c.client.ListAKSClusters(ctx, subscriptionID)
+}
+
+// GetAKSCredentials always fails with ErrReadOnly.
+func (c *ReadOnlyClient) GetAKSCredentials(_ context.Context, _ AKSCluster, _ string) error {
+	return ErrReadOnly
+}
+
+// Ensure ReadOnlyClient implements Client.
+var _ Client = (*ReadOnlyClient)(nil)
@@ -0,0 +1,52 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNoSubscriptions is returned by SwitchToTenant when no usable
+// subscription of the tenant is known.
+var ErrNoSubscriptions = errors.New("no subscriptions in tenant")
+
+// SwitchToTenant switches to a tenant without a new login, by switching to
+// one of its subscriptions. subs are the candidates in order of preference;
+// the first usable one in the tenant is switched to. It fails with
+// ErrAuthRequired when the tenant has no valid token and with
+// ErrNoSubscriptions when there is nothing to switch to. Both mean an
+// interactive login is needed.
+func SwitchToTenant(ctx context.Context, c Client, tenantID string, subs []Subscription) (*Subscription, error) {
+	var target *Subscription
+	for i := range subs {
+		if strings.EqualFold(subs[i].TenantID, tenantID) && usable(subs[i]) {
+			target = &subs[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, ErrNoSubscriptions
+	}
+
+	// az account set never checks the login, so the token is checked first
+	// to avoid switching to a tenant that then fails every command.
+	if _, err := c.GetAccessToken(ctx, target.TenantID); err != nil {
+		return nil, err
+	}
+	if err := c.SetSubscription(ctx, target.ID); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// NeedsLogin reports whether a SwitchToTenant failure calls for an
+// interactive login.
+func NeedsLogin(err error) bool {
+	return errors.Is(err, ErrAuthRequired) || errors.Is(err, ErrNoSubscriptions)
+}
+
+// usable reports whether a subscription can be switched to. Disabled and
+// deleted subscriptions are read-only at best.
+func usable(sub Subscription) bool {
+	return !strings.EqualFold(sub.State, "Disabled") && !strings.EqualFold(sub.State, "Deleted")
+}
@@ -0,0 +1,75 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSwitchToTenant(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Old", ID: "old", TenantID: "ta", State: "Disabled"},
+		{Name: "Recent", ID: "recent", TenantID: "ta", State: "Enabled"},
+		{Name: "Other", ID: "other", TenantID: "tb", State: "Enabled"},
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		client := NewMockClient()
+		sub, err := SwitchToTenant(context.Background(), client, "TA", subs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sub.ID != "recent" || strings.Join(client.Calls.SetSubscription, ",") != "recent" {
+			t.Errorf("expected a switch to the first usable subscription, got %s and %v", sub.ID, client.Calls.SetSubscription)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		client := NewMockClient()
+		client.GetAccessTokenFunc = func(_ context.Context, _ string) (*AccessToken, error) {
+			return nil, fmt.Errorf("%w: %w: AADSTS700082", ErrCommandFailed, ErrAuthRequired)
+		}
+		_, err := SwitchToTenant(context.Background(), client, "ta", subs)
+		if !errors.Is(err, ErrAuthRequired) || !NeedsLogin(err) {
+			t.Errorf("expected ErrAuthRequired, got %v", err)
+		}
+		if len(client.Calls.SetSubscription) != 0 {
+			t.Error("expected no switch without a token")
+		}
+	})
+
+	t.Run("no subscriptions", func(t *testing.T) {
+		client := NewMockClient()
+		_, err := SwitchToTenant(context.Background(), client, "tc", subs)
+		if !errors.Is(err, ErrNoSubscriptions) || !NeedsLogin(err) {
+			t.Errorf("expected ErrNoSubscriptions, got %v", err)
+		}
+	})
+
+	t.Run("other failure", func(t *testing.T) {
+		client := NewMockClient()
+		client.SetSubscriptionFunc = func(_ context.Context, _ string) error {
+			return fmt.Errorf("%w: network unreachable", ErrCommandFailed)
+		}
+		if _, err := SwitchToTenant(context.Background(), client, "tb", subs); err == nil || NeedsLogin(err) {
+			t.Errorf("expected a failure without a login, got %v", err)
+		}
+	})
+}
+
+func TestLoginArgs(t *testing.T) {
+	if got := LoginArgs("ta", LoginOptions{}); !slices.Equal(got, []string{"login", "--tenant", "ta"}) {
+		t.Errorf("unexpected args: %v", got)
+	}
+	got := LoginArgs("ta", LoginOptions{AllowNoSubscriptions: true})
+	if !slices.Contains(got, "--allow-no-subscriptions") {
+		t.Errorf("expected a tenant-level login, got %v", got)
+	}
+	got = LoginArgs("ta", LoginOptions{UseDeviceCode: true})
+	if !slices.Contains(got, "--use-device-code") {
+		t.Errorf("expected a device code login, got %v", got)
+	}
+}
@@ -1,6 +1,11 @@
 // Package azure provides Azure CLI wrapper functionality.
 package azure
 
+import (
+	"strings"
+	"time"
+)
+
 // Account represents the current Azure account information.
 type Account struct {
 	EnvironmentName   string `json:"environmentName"`
@@ -33,6 +38,10 @@ type Subscription struct {
 	TenantDisplayName string `json:"tenantDisplayName"`
 	TenantID          string `json:"tenantId"`
 	User              User   `json:"user"`
+
+	// Tags are the subscription's ARM tags. az account list does not return
+	// them, so they are only set once fetched with GetSubscriptionTags.
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // Tenant represents an Azure AD tenant/directory.
@@ -48,6 +57,41 @@ type Tenant struct {
 	TenantBrandName string   `json:"tenantBrandingLogoUrl,omitempty"`
 }
 
+// AccessToken is an access token for a tenant, as printed by az account
+// get-access-token.
+type AccessToken struct {
+	AccessToken  string `json:"accessToken"`
+	ExpiresOn    int64  `json:"expires_on"`
+	Subscription string `json:"subscription"`
+	Tenant       string `json:"tenant"`
+	TokenType    string `json:"tokenType"`
+}
+
+// Expiry returns when the token expires.
+func (t AccessToken) Expiry() time.Time {
+	return time.Unix(t.ExpiresOn, 0)
+}
+
+// AKSCluster represents an Azure Kubernetes Service cluster.
+type AKSCluster struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	ResourceGroup     string `json:"resourceGroup"`
+	Location          string `json:"location"`
+	KubernetesVersion string `json:"kubernetesVersion"`
+}
+
+// SubscriptionID returns the subscription ID parsed from the cluster's resource ID.
+func (c AKSCluster) SubscriptionID() string {
+	parts := strings.Split(strings.Trim(c.ID, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "subscriptions") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
 // Title returns a display title for the subscription.
 func (s Subscription) Title() string {
 	return s.Name
@@ -0,0 +1,52 @@
+package azure
+
+import (
+	"context"
+	"strings"
+)
+
+// Users returns the distinct users the subscriptions are signed in with, in
+// order of appearance.
+func Users(subs []Subscription) []User {
+	var users []User
+	seen := make(map[string]bool)
+	for i := range subs {
+		name := strings.ToLower(subs[i].User.Name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		users = append(users, subs[i].User)
+	}
+	return users
+}
+
+// SwitchUser switches to a subscription using the credentials of another
+// signed-in user. If the Azure CLI holds no token of that user for the
+// subscription's tenant, the subscription keeps its previous user, the
+// previous default subscription is restored and the error, usually wrapping
+// ErrAuthRequired, is returned so that an interactive login can follow.
+func SwitchUser(ctx context.Context, c Client, sub Subscription, user User) error {
+	previous, err := c.GetCurrentAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.SetSubscriptionUser(ctx, sub.ID, user); err != nil {
+		return err
+	}
+
+	err = c.SetSubscription(ctx, sub.ID)
+	switched := err == nil
+	if switched {
+		_, err = c.GetAccessToken(ctx, sub.TenantID)
+	}
+	if err != nil {
+		_ = c.SetSubscriptionUser(ctx, sub.ID, sub.User)
+		if switched && !strings.EqualFold(previous.ID, sub.ID) {
+			_ = c.SetSubscription(ctx, previous.ID)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUsers(t *testing.T) {
+	subs := []Subscription{
+		{ID: "1", User: User{Name: "me@contoso.com", Type: "user"}},
+		{ID: "2", User: User{Name: "admin@contoso.com", Type: "user"}},
+		{ID: "3", User: User{Name: "ME@contoso.com", Type: "user"}},
+		{ID: "4"},
+	}
+
+	users := Users(subs)
+	if len(users) != 2 || users[0].Name != "me@contoso.com" || users[1].Name != "admin@contoso.com" {
+		t.Errorf("expected two distinct users, got %v", users)
+	}
+}
+
+func TestSwitchUser(t *testing.T) {
+	sub := Subscription{ID: "sub", TenantID: "tid", User: User{Name: "me@contoso.com"}}
+	admin := User{Name: "admin@contoso.com", Type: "user"}
+
+	client := NewMockClient()
+	if err := SwitchUser(context.Background(), client, sub, admin); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(client.Calls.SetSubscriptionUser, ",") != "sub=admin@contoso.com" ||
+		strings.Join(client.Calls.SetSubscription, ",") != "sub" {
+		t.Errorf("expected the user to be set and sub switched to, got %v and %v",
+			client.Calls.SetSubscriptionUser, client.Calls.SetSubscription)
+	}
+
+	// Without a token of the new user, the previous user is restored.
+	client = NewMockClient()
+	client.GetAccessTokenFunc = func(_ context.Context, _ string) (*AccessToken, error) {
+		return nil, fmt.Errorf("%w: %w: AADSTS50076", ErrCommandFailed, ErrAuthRequired)
+	}
+	if err := SwitchUser(context.Background(), client, sub, admin); !errors.Is(err, ErrAuthRequired) {
+		t.Fatalf("expected ErrAuthRequired, got %v", err)
+	}
+	if strings.Join(client.Calls.SetSubscriptionUser, ",") != "sub=admin@contoso.com,sub=me@contoso.com" {
+		t.Errorf("expected the previous user to be restored, got %v", client.Calls.SetSubscriptionUser)
+	}
+	if strings.Join(client.Calls.SetSubscription, ",") != "sub,00000000-0000-0000-0000-000000000001" {
+		t.Errorf("expected the previous default subscription to be restored, got %v", client.Calls.SetSubscription)
+	}
+}
+
+func TestSwitchUser_SameSubscriptionNotRestored(t *testing.T) {
+	sub := Subscription{ID: "sub", TenantID: "tid", User: User{Name: "me@contoso.com"}}
+	admin := User{Name: "admin@contoso.com", Type: "user"}
+
+	client := NewMockClient()
+	client.GetCurrentAccountFunc = func(_ context.Context) (*Account, error) {
+		return &Account{ID: "sub", TenantID: "tid"}, nil
+	}
+	client.GetAccessTokenFunc = func(_ context.Context, _ string) (*AccessToken, error) {
+		return nil, ErrAuthRequired
+	}
+	if err := SwitchUser(context.Background(), client, sub, admin); !errors.Is(err, ErrAuthRequired) {
+		t.Fatalf("expected ErrAuthRequired, got %v", err)
+	}
+	if strings.Join(client.Calls.SetSubscription, ",") != "sub" {
+		t.Errorf("expected no switch back to the same subscription, got %v", client.Calls.SetSubscription)
+	}
+}
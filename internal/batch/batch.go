@@ -0,0 +1,205 @@
+// Package batch runs a command in many subscriptions, each with its own
+// isolated Azure CLI environment.
+package batch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azenv"
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// IsolateFunc prepares the environment for running in a subscription. It
+// returns extra environment variables and a cleanup function.
+type IsolateFunc func(sub azure.Subscription) (env []string, cleanup func() error, err error)
+
+// Result is the outcome of running the command in one subscription.
+type Result struct {
+	SubscriptionID   string  `json:"subscriptionId"`
+	SubscriptionName string  `json:"subscriptionName"`
+	TenantID         string  `json:"tenantId"`
+	ExitCode         int     `json:"exitCode"`
+	Error            string  `json:"error,omitempty"`
+	DurationSeconds  float64 `json:"durationSeconds"`
+	Output           string  `json:"output"`
+}
+
+// Succeeded reports whether the command exited successfully.
+func (r *Result) Succeeded() bool {
+	return r.ExitCode == 0 && r.Error == ""
+}
+
+// Report aggregates the results of a batch run.
+type Report struct {
+	Command   []string `json:"command"`
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Results   []Result `json:"results"`
+}
+
+// Runner runs a command across subscriptions.
+type Runner struct {
+	// Parallel is the number of subscriptions processed at once. Values below 1 mean 1.
+	Parallel int
+
+	// Output receives every subscription's output, one line at a time,
+	// prefixed with the subscription name.
+	Output io.Writer
+
+	// Isolate prepares each subscription's environment. Defaults to an azenv.Env.
+	Isolate IsolateFunc
+}
+
+// Run executes argv once per subscription and returns a report whose results
+// are in the same order as subs.
+func (r *Runner) Run(ctx context.Context, subs []azure.Subscription, argv []string) Report {
+	parallel := max(r.Parallel, 1)
+	isolate := r.Isolate
+	if isolate == nil {
+		isolate = isolateWithAzenv
+	}
+	out := r.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	report := Report{
+		Command: argv,
+		Results: make([]Result, len(subs)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+
+	for i := range subs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			w := &prefixWriter{prefix: "[" + subs[i].Name + "] ", out: out, mu: &mu}
+			report.Results[i] = runOne(ctx, subs[i], argv, isolate, w)
+			w.Flush()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range report.Results {
+		if report.Results[i].Succeeded() {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+
+	return report
+}
+
+// runOne runs argv in a single subscription.
+func runOne(ctx context.Context, sub azure.Subscription, argv []string, isolate IsolateFunc, w *prefixWriter) Result {
+	result := Result{
+		SubscriptionID:   sub.ID,
+		SubscriptionName: sub.Name,
+		TenantID:         sub.TenantID,
+	}
+	start := time.Now()
+	defer func() {
+		result.DurationSeconds = time.Since(start).Seconds()
+	}()
+
+	env, cleanup, err := isolate(sub)
+	if err != nil {
+		result.ExitCode = -1
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = cleanup() }()
+
+	var captured bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = io.MultiWriter(w, &captured)
+	cmd.Stderr = io.MultiWriter(w, &captured)
+
+	err = cmd.Run()
+	result.Output = captured.String()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			result.ExitCode = -1
+			result.Error = err.Error()
+		}
+	}
+
+	return result
+}
+
+// ShellCommand returns the argv that runs command through the system shell.
+func ShellCommand(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"sh", "-c", command}
+}
+
+// isolateWithAzenv prepares an isolated Azure CLI config directory.
+func isolateWithAzenv(sub azure.Subscription) ([]string, func() error, error) {
+	env, err := azenv.New(sub)
+	if err != nil {
+		return nil, nil, err
+	}
+	return env.Environ(), env.Close, nil
+}
+
+// prefixWriter writes complete lines to out, each prefixed, holding back any
+// trailing partial line until it is completed or flushed.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := w.buf.Next(i + 1)
+		if err := w.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes any trailing partial line.
+func (w *prefixWriter) Flush() {
+	if w.buf.Len() > 0 {
+		line := append(w.buf.Bytes(), '\n')
+		w.buf.Reset()
+		_ = w.writeLine(line)
+	}
+}
+
+// writeLine writes one prefixed line while holding the shared lock.
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.out, w.prefix+string(line))
+	return err
+}
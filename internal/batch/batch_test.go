@@ -0,0 +1,69 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// stubIsolate exposes the subscription ID to the command without touching ~/.azure.
+func stubIsolate(sub azure.Subscription) ([]string, func() error, error) {
+	if sub.ID == "broken" {
+		return nil, nil, errors.New("no profile")
+	}
+	return []string{"SUB_ID=" + sub.ID}, func() error { return nil }, nil
+}
+
+func TestRunner_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses POSIX shell")
+	}
+
+	subs := []azure.Subscription{
+		{Name: "Prod", ID: "prod-id"},
+		{Name: "Dev", ID: "dev-id"},
+		{Name: "Broken", ID: "broken"},
+	}
+
+	var out bytes.Buffer
+	r := &Runner{Parallel: 2, Output: &out, Isolate: stubIsolate}
+
+	report := r.Run(context.Background(), subs, []string{"sh", "-c", `echo "in $SUB_ID"; printf partial; [ "$SUB_ID" = prod-id ]`})
+
+	if report.Succeeded != 1 || report.Failed != 2 {
+		t.Errorf("expected 1 succeeded and 2 failed, got %d/%d", report.Succeeded, report.Failed)
+	}
+
+	// Results keep the input order.
+	if report.Results[0].SubscriptionName != "Prod" || report.Results[1].SubscriptionName != "Dev" {
+		t.Errorf("unexpected result order: %+v", report.Results)
+	}
+
+	if report.Results[1].ExitCode != 1 {
+		t.Errorf("expected Dev to exit 1, got %d", report.Results[1].ExitCode)
+	}
+
+	if report.Results[2].Error == "" {
+		t.Error("expected an error for the subscription that could not be isolated")
+	}
+
+	if report.Results[0].Output != "in prod-id\npartial" {
+		t.Errorf("unexpected captured output %q", report.Results[0].Output)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for _, want := range []string{"[Prod] in prod-id", "[Prod] partial", "[Dev] in dev-id", "[Dev] partial"} {
+		found := false
+		for _, line := range lines {
+			found = found || line == want
+		}
+		if !found {
+			t.Errorf("expected line %q in output:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Package config loads and validates the azswitch user configuration file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigPath is the environment variable that overrides the config file location.
+const EnvConfigPath = "AZSWITCH_CONFIG"
+
+// Views that can be shown when the TUI starts.
+const (
+	ViewSubscriptions = "subscriptions"
+	ViewDirectories   = "directories"
+)
+
+// Views lists the valid values of Config.DefaultView.
+var Views = []string{ViewSubscriptions, ViewDirectories}
+
+// Themes lists the valid values of Config.Theme. The auto theme adapts to
+// the terminal background.
+var Themes = []string{"auto", "dark", "light", "high-contrast", "monochrome"}
+
+// Sort orders of subscription lists.
+const (
+	SortName   = "name"
+	SortTenant = "tenant"
+	SortState  = "state"
+	SortRecent = "recent"
+	SortUsage  = "usage"
+)
+
+// Sorts lists the valid values of List.Sort, in the order the TUI cycles through them.
+var Sorts = []string{SortName, SortTenant, SortState, SortRecent, SortUsage}
+
+// Groupings of subscription lists.
+const (
+	GroupNone   = "none"
+	GroupTenant = "tenant"
+	GroupCloud  = "cloud"
+	GroupUser   = "user"
+)
+
+// Groups lists the valid values of List.Group, in the order the TUI cycles through them.
+var Groups = []string{GroupNone, GroupTenant, GroupCloud, GroupUser}
+
+// Backends that list subscriptions and tenants.
+const (
+	BackendCLI = "cli"
+	BackendARM = "arm"
+)
+
+// Backends lists the valid values of Azure.Backend.
+var Backends = []string{BackendCLI, BackendARM}
+
+// ColorNames maps the color names accepted in accents to ANSI color numbers.
+var ColorNames = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+	"orange":  "208",
+	"purple":  "93",
+	"pink":    "212",
+}
+
+// KeyActions lists the TUI actions whose key bindings can be configured.
+var KeyActions = []string{"up", "down", "select", "tab", "help", "quit", "refresh", "back", "clusters", "mark", "filter", "actions", "sort", "group", "expand", "collapse"}
+
+// DefaultKeys holds the default key bindings of each action in KeyActions.
+var DefaultKeys = map[string][]string{
+	"up":       {"up", "k"},
+	"down":     {"down", "j"},
+	"select":   {"enter"},
+	"tab":      {"tab"},
+	"help":     {"?"},
+	"quit":     {"q", "ctrl+c"},
+	"refresh":  {"r"},
+	"back":     {"esc"},
+	"clusters": {"K"},
+	"mark":     {"space"},
+	"filter":   {"/"},
+	"actions":  {"a"},
+	"sort":     {"s"},
+	"group":    {"g"},
+	"expand":   {"right", "l"},
+	"collapse": {"left", "h"},
+}
+
+// ReservedKey always quits the TUI, whatever the bindings say.
+const ReservedKey = "ctrl+c"
+
+// Config represents the azswitch configuration file.
+type Config struct {
+	// DefaultView is the TUI tab shown at startup.
+	DefaultView string `yaml:"default_view,omitempty"`
+
+	// Theme is the TUI color theme.
+	Theme string `yaml:"theme,omitempty"`
+
+	// Keys overrides the key bindings of TUI actions.
+	Keys map[string][]string `yaml:"keys,omitempty"`
+
+	// Aliases maps short names to subscription IDs or names.
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+
+	// Favorites lists subscription IDs or names to highlight.
+	Favorites []string `yaml:"favorites,omitempty"`
+
+	// Accents maps subscription IDs or names to the color of the TUI header
+	// while they are active: a color name, an ANSI number or a #rrggbb value.
+	Accents map[string]string `yaml:"accents,omitempty"`
+
+	// List sets the order of subscription lists, in the TUI and with --list.
+	List List `yaml:"list"`
+
+	// Behavior holds behavior toggles.
+	Behavior Behavior `yaml:"behavior"`
+
+	// Azure sets how subscriptions and tenants are listed.
+	Azure Azure `yaml:"azure"`
+
+	// Tags enables fetching subscription tags, for badges and filters.
+	Tags Tags `yaml:"tags"`
+
+	// Commands are offered in the TUI to run across the marked subscriptions.
+	Commands []Command `yaml:"commands,omitempty"`
+
+	// Hooks configures commands run around subscription and tenant switches.
+	Hooks Hooks `yaml:"hooks"`
+
+	// Kubernetes configures AKS kubeconfig context sync after switching subscriptions.
+	Kubernetes Kubernetes `yaml:"kubernetes"`
+}
+
+// List sets how subscription lists are sorted and grouped.
+type List struct {
+	// Sort is one of Sorts. Recent and usage order by the switches azswitch
+	// has recorded.
+	Sort string `yaml:"sort"`
+
+	// Group is one of Groups.
+	Group string `yaml:"group"`
+}
+
+// Behavior holds behavior toggles.
+type Behavior struct {
+	// ReadOnly starts azswitch in read-only mode, where nothing can be switched.
+	ReadOnly bool `yaml:"read_only"`
+
+	// QuitAfterSwitch exits the TUI after a successful switch.
+	QuitAfterSwitch bool `yaml:"quit_after_switch"`
+
+	// DisableMouse leaves the mouse to the terminal, for selecting text.
+	DisableMouse bool `yaml:"disable_mouse"`
+
+	// Inline renders a compact picker below the prompt instead of using the
+	// whole terminal, keeping the scrollback.
+	Inline bool `yaml:"inline"`
+
+	// InlineHeight is the most lines the inline picker uses. Zero means the default.
+	InlineHeight int `yaml:"inline_height,omitempty"`
+}
+
+// Azure sets how azswitch reads from Azure.
+type Azure struct {
+	// Backend is one of Backends: cli lists subscriptions and tenants with
+	// the Azure CLI, arm from Azure Resource Manager with the Azure CLI's
+	// tokens. Switching and logins always use the Azure CLI.
+	Backend string `yaml:"backend"`
+}
+
+// Tags configures the subscription tags fetched from ARM, which az account
+// list does not return.
+type Tags struct {
+	// Enabled fetches the tags of every subscription, caching them for TTL.
+	Enabled bool `yaml:"enabled"`
+
+	// Show lists the tag names shown as badges in the TUI, in order. Empty
+	// shows every tag.
+	Show []string `yaml:"show,omitempty"`
+
+	// TTL is how long fetched tags are cached. Zero means a day.
+	TTL time.Duration `yaml:"ttl,omitempty"`
+}
+
+// Kubernetes configures the AKS kubeconfig integration.
+type Kubernetes struct {
+	// Enabled offers to switch the kubectl context after a subscription switch.
+	Enabled bool `yaml:"enabled"`
+
+	// Kubeconfig overrides the kubeconfig file to update.
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+
+	// Contexts maps AKS cluster names to existing kubeconfig context names.
+	Contexts map[string]string `yaml:"contexts,omitempty"`
+}
+
+// Command is a shell command the TUI can run in each marked subscription,
+// with the subscription's isolated Azure CLI environment.
+type Command struct {
+	// Name is shown in the TUI's actions menu.
+	Name string `yaml:"name"`
+
+	// Command is run through the system shell.
+	Command string `yaml:"command"`
+
+	// Parallel is the number of subscriptions processed at once. Defaults to 1.
+	Parallel int `yaml:"parallel,omitempty"`
+}
+
+// Hooks holds the commands run before and after a switch.
+type Hooks struct {
+	// PreSwitch hooks run before switching. A failing pre-switch hook vetoes the switch.
+	PreSwitch []Hook `yaml:"pre_switch,omitempty"`
+
+	// PostSwitch hooks run after a successful switch.
+	PostSwitch []Hook `yaml:"post_switch,omitempty"`
+}
+
+// Hook is a single shell command run around a switch.
+type Hook struct {
+	// Command is run through the system shell.
+	Command string `yaml:"command"`
+
+	// Subscriptions limits the hook to switches targeting these subscription IDs or names.
+	Subscriptions []string `yaml:"subscriptions,omitempty"`
+
+	// Tenants limits the hook to switches targeting these tenant IDs.
+	Tenants []string `yaml:"tenants,omitempty"`
+
+	// Timeout bounds the hook's run time. Zero means no timeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// DefaultInlineHeight is the height of the inline picker when none is configured.
+const DefaultInlineHeight = 10
+
+// Default returns the default configuration.
+func Default() *Config {
+	return &Config{
+		DefaultView: ViewSubscriptions,
+		Theme:       "auto",
+		List:        List{Sort: SortName, Group: GroupNone},
+		Azure:       Azure{Backend: BackendCLI},
+	}
+}
+
+// DefaultPath returns the config file location, honoring AZSWITCH_CONFIG and XDG_CONFIG_HOME.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path, nil
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+	}
+
+	return filepath.Join(dir, "azswitch", "config.yaml"), nil
+}
+
+// Load reads and validates the configuration at path. A missing file yields
+// the default configuration.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Default(), nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return Parse(path, data)
+}
+
+// Parse decodes and validates configuration data read from path. Errors
+// name the file and line of the offending setting.
+func Parse(path string, data []byte) (*Config, error) {
+	cfg := Default()
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := cfg.validate(path, &root); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Marshal encodes the configuration as YAML.
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// EffectiveKeys returns the key bindings of every action: the defaults with
+// the configured overrides applied.
+func (c *Config) EffectiveKeys() map[string][]string {
+	keys := make(map[string][]string, len(DefaultKeys))
+	for action, defaults := range DefaultKeys {
+		keys[action] = defaults
+	}
+	for action, overrides := range c.Keys {
+		if _, ok := keys[action]; ok {
+			keys[action] = overrides
+		}
+	}
+	return keys
+}
+
+// ResolveColor converts a color name, an ANSI color number (0-255) or a
+// #rrggbb value to the ANSI number or hex form used by terminal styling.
+func ResolveColor(color string) (string, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if ansi, ok := ColorNames[color]; ok {
+		return ansi, true
+	}
+	if n, err := strconv.Atoi(color); err == nil {
+		return color, n >= 0 && n <= 255
+	}
+	if len(color) == 7 && color[0] == '#' {
+		if _, err := strconv.ParseUint(color[1:], 16, 32); err == nil {
+			return color, true
+		}
+	}
+	return "", false
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
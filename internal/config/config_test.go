@@ -0,0 +1,291 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.DefaultView != ViewSubscriptions || cfg.Theme != "auto" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestParse_Valid(t *testing.T) {
+	data := `
+default_view: directories
+keys:
+  down: [down, ctrl+n]
+aliases:
+  prod: 00000000-0000-0000-0000-000000000001
+favorites: [Production]
+behavior:
+  read_only: true
+azure:
+  backend: arm
+hooks:
+  post_switch:
+    - command: echo hi
+      timeout: 30s
+`
+	cfg, err := Parse("config.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.DefaultView != ViewDirectories {
+		t.Errorf("expected default view directories, got %s", cfg.DefaultView)
+	}
+
+	if cfg.Theme != "auto" {
+		t.Errorf("expected unset theme to keep its default, got %s", cfg.Theme)
+	}
+
+	if !cfg.Behavior.ReadOnly {
+		t.Error("expected read_only to be set")
+	}
+
+	if cfg.Azure.Backend != BackendARM {
+		t.Errorf("expected the arm backend, got %s", cfg.Azure.Backend)
+	}
+
+	if cfg.Hooks.PostSwitch[0].Timeout != 30*time.Second {
+		t.Errorf("expected 30s timeout, got %v", cfg.Hooks.PostSwitch[0].Timeout)
+	}
+}
+
+func TestParse_UnknownFieldReportsLine(t *testing.T) {
+	data := "behavior:\n  read_only: true\n  readonly: true\n"
+
+	_, err := Parse("config.yaml", []byte(data))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "readonly") {
+		t.Errorf("expected error to point at line 3, got %v", err)
+	}
+}
+
+func TestParse_ValidationErrors(t *testing.T) {
+	data := `theme: dark
+default_view: tenants
+keys:
+  up: [k]
+  jump: [x]
+hooks:
+  pre_switch:
+    - command: ok
+    - command: ""
+tags:
+  ttl: -1h
+azure:
+  backend: rest
+`
+	_, err := Parse("config.yaml", []byte(data))
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	for _, want := range []string{
+		"config.yaml:2: default_view: must be one of",
+		"config.yaml:5: keys.jump: unknown action",
+		"config.yaml:9: hooks.pre_switch.1.command: must not be empty",
+		"config.yaml:11: tags.ttl: must not be negative",
+		"config.yaml:13: azure.backend: must be one of cli, arm",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestTemplate_IsValid(t *testing.T) {
+	if _, err := Parse("template", []byte(Template)); err != nil {
+		t.Errorf("template should be valid: %v", err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(EnvConfigPath, "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join("/xdg", "azswitch", "config.yaml") {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	t.Setenv(EnvConfigPath, "/custom.yaml")
+	if path, _ := DefaultPath(); path != "/custom.yaml" {
+		t.Errorf("expected AZSWITCH_CONFIG to win, got %s", path)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	cfg := Default()
+	cfg.Favorites = []string{"Production"}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Favorites) != 1 || loaded.Favorites[0] != "Production" {
+		t.Errorf("unexpected favorites %v", loaded.Favorites)
+	}
+}
+
+func TestParse_KeyConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"default of another action", "keys:\n  up: [up, j]\n", `config.yaml:2: keys.up: "j" is already bound to down`},
+		{"two overrides", "keys:\n  refresh: [x]\n  back: [x]\n", `keys.back: "x" is already bound to refresh`},
+		{"reserved key", "keys:\n  help: [ctrl+c]\n", `keys.help: "ctrl+c" is reserved for quitting`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("config.yaml", []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParse_KeysWithoutConflicts(t *testing.T) {
+	data := "keys:\n  up: [up, ctrl+p]\n  down: [down, ctrl+n]\n  quit: [ctrl+q]\n"
+
+	cfg, err := Parse("config.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := cfg.EffectiveKeys()
+	if strings.Join(keys["quit"], ",") != "ctrl+q" {
+		t.Errorf("expected quit override, got %v", keys["quit"])
+	}
+	if strings.Join(keys["select"], ",") != "enter" {
+		t.Errorf("expected select default, got %v", keys["select"])
+	}
+}
+
+func TestParse_Accents(t *testing.T) {
+	data := "accents:\n  Production: red\n  Staging: \"#FFAA00\"\n  Dev: \"42\"\n  Broken: chartreuse-ish\n"
+
+	_, err := Parse("config.yaml", []byte(data))
+	if err == nil || !strings.Contains(err.Error(), "config.yaml:5: accents.Broken:") {
+		t.Fatalf("expected error for accents.Broken on line 5, got %v", err)
+	}
+	if strings.Count(err.Error(), "accents.") != 1 {
+		t.Errorf("expected only the invalid accent to be reported, got %v", err)
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"red", "1", true},
+		{"Orange", "208", true},
+		{"196", "196", true},
+		{"256", "", false},
+		{"#ff0000", "#ff0000", true},
+		{"#ff00", "", false},
+		{"#gg0000", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ResolveColor(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ResolveColor(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAddFavorites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azswitch", "config.yaml")
+
+	if err := AddFavorites(path, []string{"id-1"}); err != nil {
+		t.Fatalf("unexpected error creating the file: %v", err)
+	}
+
+	data := "# my settings\ntheme: dark # keep\nfavorites:\n  - Production\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddFavorites(path, []string{"production", "id-2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cfg.Favorites, ",") != "Production,id-2" {
+		t.Errorf("expected Production,id-2, got %v", cfg.Favorites)
+	}
+
+	out, _ := os.ReadFile(path)
+	if !strings.Contains(string(out), "# my settings") || !strings.Contains(string(out), "# keep") {
+		t.Errorf("expected comments to be kept, got:\n%s", out)
+	}
+}
+
+func TestSetList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "# my settings\nlist:\n  sort: name # keep\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetList(path, List{Sort: SortRecent, Group: GroupTenant}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.List.Sort != SortRecent || cfg.List.Group != GroupTenant {
+		t.Errorf("expected recent/tenant, got %+v", cfg.List)
+	}
+
+	out, _ := os.ReadFile(path)
+	if !strings.Contains(string(out), "# my settings") || !strings.Contains(string(out), "# keep") {
+		t.Errorf("expected comments to be kept, got:\n%s", out)
+	}
+
+	if err := SetList(path, List{Sort: "size", Group: GroupNone}); err == nil {
+		t.Error("expected an invalid sort order to be rejected")
+	}
+}
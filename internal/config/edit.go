@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddFavorites appends subscriptions to the favorites of the config file at
+// path, creating the file if needed. Favorites already listed are skipped,
+// and comments elsewhere in the file are kept.
+func AddFavorites(path string, favorites []string) error {
+	return edit(path, func(root *yaml.Node) error {
+		_, list := child(root, "favorites")
+		if list == nil {
+			list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			root.Content = append(root.Content, scalar("favorites"), list)
+		}
+		if list.Kind == yaml.ScalarNode && list.Tag == "!!null" {
+			*list = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		}
+		if list.Kind != yaml.SequenceNode {
+			return fmt.Errorf("%s:%d: favorites: expected a list", path, list.Line)
+		}
+
+		for _, favorite := range favorites {
+			if !containsFold(list.Content, favorite) {
+				list.Content = append(list.Content, scalar(favorite))
+			}
+		}
+		return nil
+	})
+}
+
+// SetList saves the sort order and grouping of subscription lists to the
+// config file at path, creating the file if needed. Comments are kept.
+func SetList(path string, list List) error {
+	return edit(path, func(root *yaml.Node) error {
+		_, section := child(root, "list")
+		if section == nil {
+			section = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			root.Content = append(root.Content, scalar("list"), section)
+		}
+		if section.Kind == yaml.ScalarNode && section.Tag == "!!null" {
+			*section = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		if section.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s:%d: list: expected a mapping", path, section.Line)
+		}
+
+		setScalar(section, "sort", list.Sort)
+		setScalar(section, "group", list.Group)
+		return nil
+	})
+}
+
+// edit applies change to the top-level mapping of the config file at path,
+// then validates the result and writes it back, creating the file if needed.
+func edit(path string, change func(root *yaml.Node) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping at the top level", path)
+	}
+	if err := change(root); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if _, err := Parse(path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// scalar returns a string node.
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// setScalar sets a key of a mapping to a string, keeping the key's comments.
+func setScalar(mapping *yaml.Node, key, value string) {
+	if _, node := child(mapping, key); node != nil {
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, LineComment: node.LineComment}
+		return
+	}
+	mapping.Content = append(mapping.Content, scalar(key), scalar(value))
+}
+
+// containsFold reports whether a sequence has a scalar equal to value, ignoring case.
+func containsFold(items []*yaml.Node, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item.Value, value) {
+			return true
+		}
+	}
+	return false
+}
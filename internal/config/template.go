@@ -0,0 +1,79 @@
+package config
+
+// Template is written by 'azswitch config edit' when no config file exists.
+const Template = `# azswitch configuration
+# Run 'azswitch config validate' after editing.
+
+# Tab shown when the TUI starts: subscriptions or directories.
+default_view: subscriptions
+
+# Color theme: auto (follows the terminal background), dark, light,
+# high-contrast or monochrome. NO_COLOR or --no-color turn colors off.
+theme: auto
+
+# Key bindings per action: up, down, select, tab, help, quit, refresh, back,
+# clusters, mark, filter, actions, sort, group, expand, collapse.
+# A key may only be bound to one action; ctrl+c always quits.
+# keys:
+#   up: [up, k, ctrl+p]
+#   down: [down, j, ctrl+n]
+
+# Short names for subscriptions, usable wherever a subscription is expected.
+# aliases:
+#   prod: 00000000-0000-0000-0000-000000000000
+
+# Subscriptions (ID or name) marked with a star.
+# favorites:
+#   - Production
+
+# Header colors per subscription (ID or name): a color name, 0-255 or #rrggbb.
+# accents:
+#   Production: red
+
+# Order of subscription lists, in the TUI (s and g cycle) and with --list.
+# sort: name, tenant, state, recent or usage (most switched to).
+# group: none, tenant, cloud or user.
+list:
+  sort: name
+  group: none
+
+behavior:
+  read_only: false
+  quit_after_switch: false
+  # Let the terminal handle the mouse instead of clicking and scrolling in azswitch.
+  disable_mouse: false
+  # Show a compact picker below the prompt instead of a full-screen TUI.
+  inline: false
+  inline_height: 10
+
+# How subscriptions and tenants are listed: cli (az account list) or arm
+# (Azure Resource Manager, with the Azure CLI's tokens; faster with many
+# tenants). Switching and logins always use the Azure CLI. --arm picks arm.
+azure:
+  backend: cli
+
+# Subscription tags fetched from Azure Resource Manager and cached for ttl.
+# They are shown as badges and can be filtered on, e.g. tag.env=prod.
+# tags:
+#   enabled: false
+#   show: [env, owner]
+#   ttl: 24h
+
+# Commands offered in the TUI actions menu (a) to run in every marked subscription.
+# commands:
+#   - name: Resource groups
+#     command: az group list -o table
+#     parallel: 4
+
+# Commands run around switches. See the README for the environment they receive.
+# hooks:
+#   pre_switch:
+#     - command: ./scripts/check.sh
+#   post_switch:
+#     - command: az aks get-credentials -g my-rg -n my-aks --overwrite-existing
+#       subscriptions: [Production]
+#       timeout: 30s
+
+# kubernetes:
+#   enabled: false
+`
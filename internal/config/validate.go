@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError describes an invalid setting.
+type ValidationError struct {
+	// Path is the config file.
+	Path string
+
+	// Line is the line of the setting, or 0 if unknown.
+	Line int
+
+	// Field is the dotted key path of the setting.
+	Field string
+
+	// Message explains what is wrong.
+	Message string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", e.Path, e.Line, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Path, e.Field, e.Message)
+}
+
+// validator collects validation errors, locating each in the YAML tree.
+type validator struct {
+	path string
+	root *yaml.Node
+	errs []error
+}
+
+// fail records an error for the setting at the given key path.
+func (v *validator) fail(message string, keys ...string) {
+	v.errs = append(v.errs, &ValidationError{
+		Path:    v.path,
+		Line:    lineOf(v.root, keys...),
+		Field:   strings.Join(keys, "."),
+		Message: message,
+	})
+}
+
+// validate checks the settings that YAML decoding cannot.
+func (c *Config) validate(path string, root *yaml.Node) error {
+	v := &validator{path: path, root: root}
+
+	if !slices.Contains(Views, c.DefaultView) {
+		v.fail("must be one of "+strings.Join(Views, ", "), "default_view")
+	}
+
+	if !slices.Contains(Themes, c.Theme) {
+		v.fail("must be one of "+strings.Join(Themes, ", "), "theme")
+	}
+
+	if !slices.Contains(Sorts, c.List.Sort) {
+		v.fail("must be one of "+strings.Join(Sorts, ", "), "list", "sort")
+	}
+	if !slices.Contains(Groups, c.List.Group) {
+		v.fail("must be one of "+strings.Join(Groups, ", "), "list", "group")
+	}
+
+	for _, action := range sortedKeys(c.Keys) {
+		if !slices.Contains(KeyActions, action) {
+			v.fail("unknown action, must be one of "+strings.Join(KeyActions, ", "), "keys", action)
+			continue
+		}
+		if len(c.Keys[action]) == 0 {
+			v.fail("must list at least one key", "keys", action)
+		}
+	}
+
+	c.validateKeyConflicts(v)
+
+	if !slices.Contains(Backends, c.Azure.Backend) {
+		v.fail("must be one of "+strings.Join(Backends, ", "), "azure", "backend")
+	}
+
+	if c.Behavior.InlineHeight < 0 {
+		v.fail("must not be negative", "behavior", "inline_height")
+	}
+
+	for _, alias := range sortedKeys(c.Aliases) {
+		if strings.TrimSpace(c.Aliases[alias]) == "" {
+			v.fail("must name a subscription ID or name", "aliases", alias)
+		}
+	}
+
+	for _, sub := range sortedKeys(c.Accents) {
+		if _, ok := ResolveColor(c.Accents[sub]); !ok {
+			v.fail("must be a color name, an ANSI color number (0-255) or #rrggbb", "accents", sub)
+		}
+	}
+
+	if c.Tags.TTL < 0 {
+		v.fail("must not be negative", "tags", "ttl")
+	}
+	for i, name := range c.Tags.Show {
+		if strings.TrimSpace(name) == "" {
+			v.fail("must not be empty", "tags", "show", strconv.Itoa(i))
+		}
+	}
+
+	for i := range c.Commands {
+		if strings.TrimSpace(c.Commands[i].Name) == "" {
+			v.fail("must not be empty", "commands", strconv.Itoa(i), "name")
+		}
+		if strings.TrimSpace(c.Commands[i].Command) == "" {
+			v.fail("must not be empty", "commands", strconv.Itoa(i), "command")
+		}
+		if c.Commands[i].Parallel < 0 {
+			v.fail("must not be negative", "commands", strconv.Itoa(i), "parallel")
+		}
+	}
+
+	for i := range c.Hooks.PreSwitch {
+		if strings.TrimSpace(c.Hooks.PreSwitch[i].Command) == "" {
+			v.fail("must not be empty", "hooks", "pre_switch", strconv.Itoa(i), "command")
+		}
+	}
+	for i := range c.Hooks.PostSwitch {
+		if strings.TrimSpace(c.Hooks.PostSwitch[i].Command) == "" {
+			v.fail("must not be empty", "hooks", "post_switch", strconv.Itoa(i), "command")
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// validateKeyConflicts reports keys bound to more than one action, and the
+// reserved quit key bound to anything but quit. Only overridden actions are
+// reported, since the defaults do not conflict.
+func (c *Config) validateKeyConflicts(v *validator) {
+	effective := c.EffectiveKeys()
+
+	for _, action := range sortedKeys(c.Keys) {
+		if !slices.Contains(KeyActions, action) {
+			continue
+		}
+		for _, k := range c.Keys[action] {
+			if k == ReservedKey && action != "quit" {
+				v.fail(fmt.Sprintf("%q is reserved for quitting", k), "keys", action)
+				continue
+			}
+			for _, other := range KeyActions {
+				if other != action && slices.Contains(effective[other], k) {
+					v.fail(fmt.Sprintf("%q is already bound to %s", k, other), "keys", action)
+				}
+			}
+		}
+	}
+}
+
+// lineOf returns the line of the setting at the given key path, or of its
+// closest existing ancestor. Sequence items are addressed by index.
+func lineOf(root *yaml.Node, keys ...string) int {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := 0
+	for _, key := range keys {
+		keyNode, value := child(node, key)
+		if value == nil {
+			break
+		}
+		line = keyNode.Line
+		node = value
+	}
+	return line
+}
+
+// child returns the key and value nodes of a mapping key, or the item of a
+// sequence index as both.
+func child(node *yaml.Node, key string) (keyNode, value *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i], node.Content[i]
+		}
+	}
+	return nil, nil
+}
+
+// sortedKeys returns the keys of m in order, so errors are reported deterministically.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+// Package filter parses and evaluates subscription filter expressions.
+//
+// An expression is a comma-separated list of terms that must all match.
+// Each term compares a field with a value:
+//
+//	field=value   equal, ignoring case
+//	field!=value  not equal, ignoring case
+//	field~value   contains, ignoring case
+//	field!~value  does not contain, ignoring case
+//
+// A term without an operator matches names containing it.
+// Fields are name, id, tenant (ID or display name), state, cloud and user.
+// tag.NAME compares the value of the tag NAME, and tag compares each tag as
+// a NAME=VALUE pair, so tag.env=prod and tag=env=prod are the same. Tags are
+// only known once fetched.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/tags"
+)
+
+// tagPrefix starts the fields comparing a single tag.
+const tagPrefix = "tag."
+
+// ErrInvalid is returned for malformed filter expressions.
+var ErrInvalid = errors.New("invalid filter")
+
+// op is a comparison operator.
+type op string
+
+const (
+	opEqual       op = "="
+	opNotEqual    op = "!="
+	opContains    op = "~"
+	opNotContains op = "!~"
+)
+
+// term is a single field comparison.
+type term struct {
+	field string
+	op    op
+	value string
+}
+
+// Filter matches subscriptions against an expression. The zero Filter matches everything.
+type Filter struct {
+	terms []term
+}
+
+// Parse parses a filter expression.
+func Parse(expr string) (Filter, error) {
+	var f Filter
+	for _, raw := range strings.Split(expr, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		t, err := parseTerm(raw)
+		if err != nil {
+			return Filter{}, err
+		}
+		f.terms = append(f.terms, t)
+	}
+	return f, nil
+}
+
+// parseTerm parses a single field comparison.
+func parseTerm(raw string) (term, error) {
+	i := strings.IndexAny(raw, "=~")
+	if i < 0 {
+		return term{field: "name", op: opContains, value: raw}, nil
+	}
+
+	o, field := op(raw[i:i+1]), raw[:i]
+	if strings.HasSuffix(field, "!") {
+		o, field = "!"+o, strings.TrimSuffix(field, "!")
+	}
+
+	field = strings.ToLower(strings.TrimSpace(field))
+	if _, known := fields[field]; !known && (!strings.HasPrefix(field, tagPrefix) || field == tagPrefix) {
+		return term{}, fmt.Errorf("%w: unknown field %q in %q", ErrInvalid, field, raw)
+	}
+	return term{field: field, op: o, value: strings.TrimSpace(raw[i+1:])}, nil
+}
+
+// fields maps field names to the subscription values they compare against.
+var fields = map[string]func(*azure.Subscription) []string{
+	"name":   func(s *azure.Subscription) []string { return []string{s.Name} },
+	"id":     func(s *azure.Subscription) []string { return []string{s.ID} },
+	"tenant": func(s *azure.Subscription) []string { return []string{s.TenantID, s.TenantDisplayName} },
+	"state":  func(s *azure.Subscription) []string { return []string{s.State} },
+	"cloud":  func(s *azure.Subscription) []string { return []string{s.CloudName} },
+	"user":   func(s *azure.Subscription) []string { return []string{s.User.Name} },
+	"tag":    func(s *azure.Subscription) []string { return tags.Format(s.Tags, nil) },
+}
+
+// Match reports whether the subscription matches every term.
+func (f Filter) Match(sub *azure.Subscription) bool {
+	for _, t := range f.terms {
+		if !t.match(t.values(sub)) {
+			return false
+		}
+	}
+	return true
+}
+
+// UsesTags reports whether any term compares tags, which are only known
+// once fetched.
+func (f Filter) UsesTags() bool {
+	for _, t := range f.terms {
+		if t.field == "tag" || strings.HasPrefix(t.field, tagPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the subscriptions matching the filter.
+func (f Filter) Apply(subs []azure.Subscription) []azure.Subscription {
+	var matched []azure.Subscription
+	for i := range subs {
+		if f.Match(&subs[i]) {
+			matched = append(matched, subs[i])
+		}
+	}
+	return matched
+}
+
+// values returns the subscription values the term compares against.
+func (t term) values(sub *azure.Subscription) []string {
+	name, ok := strings.CutPrefix(t.field, tagPrefix)
+	if !ok {
+		return fields[t.field](sub)
+	}
+	if value, ok := tags.Lookup(sub.Tags, name); ok {
+		return []string{value}
+	}
+	return nil
+}
+
+// match reports whether any of the values satisfies the term.
+func (t term) match(values []string) bool {
+	value := strings.ToLower(t.value)
+	matched := false
+	for _, v := range values {
+		v = strings.ToLower(v)
+		switch t.op {
+		case opEqual, opNotEqual:
+			matched = matched || v == value
+		case opContains, opNotContains:
+			matched = matched || strings.Contains(v, value)
+		}
+	}
+
+	if t.op == opNotEqual || t.op == opNotContains {
+		return !matched
+	}
+	return matched
+}
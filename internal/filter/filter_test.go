@@ -0,0 +1,83 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+func testSubscriptions() []azure.Subscription {
+	return []azure.Subscription{
+		{Name: "Contoso Prod", ID: "11111111-0000-0000-0000-000000000001", TenantID: "tenant-a", TenantDisplayName: "Contoso", State: "Enabled", CloudName: "AzureCloud", Tags: map[string]string{"env": "prod", "owner": "team-a"}},
+		{Name: "Contoso Dev", ID: "11111111-0000-0000-0000-000000000002", TenantID: "tenant-a", TenantDisplayName: "Contoso", State: "Disabled", CloudName: "AzureCloud", Tags: map[string]string{"Env": "dev"}},
+		{Name: "Fabrikam Prod", ID: "22222222-0000-0000-0000-000000000001", TenantID: "tenant-b", TenantDisplayName: "Fabrikam", State: "Enabled", CloudName: "AzureUSGovernment"},
+	}
+}
+
+func names(subs []azure.Subscription) []string {
+	out := make([]string, len(subs))
+	for i := range subs {
+		out[i] = subs[i].Name
+	}
+	return out
+}
+
+func TestParse_Apply(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"", []string{"Contoso Prod", "Contoso Dev", "Fabrikam Prod"}},
+		{"prod", []string{"Contoso Prod", "Fabrikam Prod"}},
+		{"name~PROD", []string{"Contoso Prod", "Fabrikam Prod"}},
+		{"tenant=contoso", []string{"Contoso Prod", "Contoso Dev"}},
+		{"tenant=tenant-b", []string{"Fabrikam Prod"}},
+		{"state!=disabled", []string{"Contoso Prod", "Fabrikam Prod"}},
+		{"name!~dev, cloud=AzureCloud", []string{"Contoso Prod"}},
+		{"id~22222222", []string{"Fabrikam Prod"}},
+		{"tag.env=prod", []string{"Contoso Prod"}},
+		{"tag.ENV!=prod", []string{"Contoso Dev", "Fabrikam Prod"}},
+		{"tag=env=dev", []string{"Contoso Dev"}},
+		{"tag~team", []string{"Contoso Prod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := names(f.Apply(testSubscriptions()))
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_UnknownField(t *testing.T) {
+	for _, expr := range []string{"colour=blue", "tag.=prod"} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected ErrInvalid for %q, got %v", expr, err)
+		}
+	}
+}
+
+func TestFilter_UsesTags(t *testing.T) {
+	for expr, want := range map[string]bool{"prod": false, "tag~team": true, "name=a, tag.env=prod": true} {
+		f, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if f.UsesTags() != want {
+			t.Errorf("UsesTags(%q) = %v, want %v", expr, !want, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package history records which subscriptions azswitch switched to, so that
+// lists can be sorted by recent or frequent use.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// EnvPath is the environment variable that overrides the history file location.
+const EnvPath = "AZSWITCH_HISTORY"
+
+// Entry is the recorded use of one subscription.
+type Entry struct {
+	// Count is the number of switches to the subscription.
+	Count int `json:"count"`
+
+	// LastUsed is the time of the latest switch.
+	LastUsed time.Time `json:"last_used"`
+}
+
+// History maps subscription IDs to their recorded use. The zero History is
+// empty and ready to use.
+type History struct {
+	Subscriptions map[string]Entry `json:"subscriptions"`
+}
+
+// DefaultPath returns the history file location under the user cache
+// directory, honoring AZSWITCH_HISTORY.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cache, "azswitch", "history.json"), nil
+}
+
+// Load reads the history at path. A missing file yields an empty history.
+func Load(path string) (*History, error) {
+	h := &History{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Save writes the history to path, creating its directory if needed.
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Add records a switch to the subscription at the given time.
+func (h *History) Add(id string, at time.Time) {
+	if h.Subscriptions == nil {
+		h.Subscriptions = make(map[string]Entry)
+	}
+	e := h.Subscriptions[id]
+	e.Count++
+	if at.After(e.LastUsed) {
+		e.LastUsed = at
+	}
+	h.Subscriptions[id] = e
+}
+
+// Get returns the recorded use of a subscription. A nil History has none.
+func (h *History) Get(id string) Entry {
+	if h == nil {
+		return Entry{}
+	}
+	return h.Subscriptions[id]
+}
+
+// Record adds a switch to the subscription, now, to the history file at path.
+func Record(path, id string) error {
+	h, err := Load(path)
+	if err != nil {
+		return err
+	}
+	h.Add(id, time.Now())
+	return h.Save(path)
+}
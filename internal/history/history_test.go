@@ -0,0 +1,68 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	h, err := Load(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := h.Get("sub-1"); got.Count != 0 || !got.LastUsed.IsZero() {
+		t.Errorf("expected no use, got %+v", got)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azswitch", "history.json")
+
+	for _, id := range []string{"sub-1", "sub-2", "sub-1"} {
+		if err := Record(path, id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	h, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := h.Get("sub-1").Count; got != 2 {
+		t.Errorf("expected sub-1 to be used twice, got %d", got)
+	}
+	if h.Get("sub-1").LastUsed.Before(h.Get("sub-2").LastUsed) {
+		t.Error("expected sub-1 to be the latest")
+	}
+}
+
+func TestAdd_KeepsLatest(t *testing.T) {
+	var h History
+	later := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	h.Add("sub-1", later)
+	h.Add("sub-1", later.Add(-time.Hour))
+
+	if got := h.Get("sub-1"); got.Count != 2 || !got.LastUsed.Equal(later) {
+		t.Errorf("expected 2 uses, last at %v, got %+v", later, got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestGet_NilHistory(t *testing.T) {
+	var h *History
+	if h.Get("sub-1").Count != 0 {
+		t.Error("expected a nil history to be empty")
+	}
+}
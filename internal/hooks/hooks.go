@@ -0,0 +1,185 @@
+// Package hooks runs user-configured commands around subscription and tenant switches.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/batch"
+	"github.com/l2D/azswitch/internal/config"
+)
+
+// ErrVetoed is returned when a pre-switch hook exits with a non-zero status.
+var ErrVetoed = errors.New("switch vetoed by pre-switch hook")
+
+// Stage identifies when a hook runs relative to the switch.
+type Stage string
+
+// Hook stages.
+const (
+	StagePre  Stage = "pre"
+	StagePost Stage = "post"
+)
+
+// Kind identifies what is being switched.
+type Kind string
+
+// Switch kinds.
+const (
+	KindSubscription Kind = "subscription"
+	KindTenant       Kind = "tenant"
+)
+
+// Event describes a switch. Hooks receive it as AZSWITCH_* environment variables.
+type Event struct {
+	Kind Kind
+
+	OldSubscriptionID   string
+	OldSubscriptionName string
+	OldTenantID         string
+
+	NewSubscriptionID   string
+	NewSubscriptionName string
+	NewTenantID         string
+}
+
+// Environ returns the event as environment variables for the given stage.
+func (e Event) Environ(stage Stage) []string {
+	return []string{
+		"AZSWITCH_HOOK_STAGE=" + string(stage),
+		"AZSWITCH_SWITCH_KIND=" + string(e.Kind),
+		"AZSWITCH_OLD_SUBSCRIPTION_ID=" + e.OldSubscriptionID,
+		"AZSWITCH_OLD_SUBSCRIPTION_NAME=" + e.OldSubscriptionName,
+		"AZSWITCH_OLD_TENANT_ID=" + e.OldTenantID,
+		"AZSWITCH_NEW_SUBSCRIPTION_ID=" + e.NewSubscriptionID,
+		"AZSWITCH_NEW_SUBSCRIPTION_NAME=" + e.NewSubscriptionName,
+		"AZSWITCH_NEW_TENANT_ID=" + e.NewTenantID,
+	}
+}
+
+// WithOld returns a copy of the event whose old side is taken from account.
+func (e Event) WithOld(account *azure.Account) Event {
+	if account != nil {
+		e.OldSubscriptionID = account.ID
+		e.OldSubscriptionName = account.Name
+		e.OldTenantID = account.TenantID
+	}
+	return e
+}
+
+// WithNew returns a copy of the event whose new side is taken from account.
+func (e Event) WithNew(account *azure.Account) Event {
+	if account != nil {
+		e.NewSubscriptionID = account.ID
+		e.NewSubscriptionName = account.Name
+		e.NewTenantID = account.TenantID
+	}
+	return e
+}
+
+// Runner executes configured hooks. A nil Runner runs nothing.
+type Runner struct {
+	pre  []config.Hook
+	post []config.Hook
+}
+
+// NewRunner creates a runner for the configured hooks.
+func NewRunner(cfg config.Hooks) *Runner {
+	return &Runner{
+		pre:  cfg.PreSwitch,
+		post: cfg.PostSwitch,
+	}
+}
+
+// Pre runs the pre-switch hooks matching the event and returns their combined output.
+// The first failing hook stops the run and yields an error wrapping ErrVetoed.
+func (r *Runner) Pre(ctx context.Context, ev Event) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	output, err := r.run(ctx, StagePre, r.pre, ev)
+	if err != nil {
+		return output, fmt.Errorf("%w: %w", ErrVetoed, err)
+	}
+	return output, nil
+}
+
+// Post runs the post-switch hooks matching the event and returns their combined output.
+// All matching hooks run; failures are joined into the returned error.
+func (r *Runner) Post(ctx context.Context, ev Event) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	return r.run(ctx, StagePost, r.post, ev)
+}
+
+// run executes the matching hooks in order.
+func (r *Runner) run(ctx context.Context, stage Stage, hooks []config.Hook, ev Event) (string, error) {
+	var output strings.Builder
+	var errs []error
+
+	for i := range hooks {
+		hook := &hooks[i]
+		if !matches(hook, ev) {
+			continue
+		}
+
+		out, err := runHook(ctx, hook, ev.Environ(stage))
+		output.WriteString(out)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %q: %w", hook.Command, err))
+			if stage == StagePre {
+				break
+			}
+		}
+	}
+
+	return strings.TrimSpace(output.String()), errors.Join(errs...)
+}
+
+// matches reports whether the hook applies to the event's target.
+func matches(hook *config.Hook, ev Event) bool {
+	if len(hook.Subscriptions) == 0 && len(hook.Tenants) == 0 {
+		return true
+	}
+	for _, s := range hook.Subscriptions {
+		if ev.NewSubscriptionID != "" && strings.EqualFold(s, ev.NewSubscriptionID) {
+			return true
+		}
+		if ev.NewSubscriptionName != "" && strings.EqualFold(s, ev.NewSubscriptionName) {
+			return true
+		}
+	}
+	for _, t := range hook.Tenants {
+		if ev.NewTenantID != "" && strings.EqualFold(t, ev.NewTenantID) {
+			return true
+		}
+	}
+	return false
+}
+
+// runHook runs a single hook through the system shell.
+func runHook(ctx context.Context, hook *config.Hook, env []string) (string, error) {
+	if hook.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	argv := batch.ShellCommand(hook.Command)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	return output.String(), err
+}
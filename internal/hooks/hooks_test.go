@@ -0,0 +1,119 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/l2D/azswitch/internal/config"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell syntax")
+	}
+}
+
+func TestRunner_NilRunsNothing(t *testing.T) {
+	var r *Runner
+
+	output, err := r.Pre(context.Background(), Event{})
+	if err != nil || output != "" {
+		t.Errorf("expected no output and no error, got %q, %v", output, err)
+	}
+}
+
+func TestRunner_PassesEventAsEnv(t *testing.T) {
+	skipOnWindows(t)
+
+	r := NewRunner(config.Hooks{
+		PostSwitch: []config.Hook{
+			{Command: `echo "$AZSWITCH_HOOK_STAGE $AZSWITCH_OLD_SUBSCRIPTION_ID -> $AZSWITCH_NEW_SUBSCRIPTION_ID"`},
+		},
+	})
+
+	output, err := r.Post(context.Background(), Event{
+		Kind:              KindSubscription,
+		OldSubscriptionID: "old-id",
+		NewSubscriptionID: "new-id",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output != "post old-id -> new-id" {
+		t.Errorf("unexpected output %q", output)
+	}
+}
+
+func TestRunner_PreHookVetoes(t *testing.T) {
+	skipOnWindows(t)
+
+	r := NewRunner(config.Hooks{
+		PreSwitch: []config.Hook{
+			{Command: "echo refusing; exit 3"},
+			{Command: "echo never"},
+		},
+	})
+
+	output, err := r.Pre(context.Background(), Event{Kind: KindTenant})
+	if !errors.Is(err, ErrVetoed) {
+		t.Fatalf("expected ErrVetoed, got %v", err)
+	}
+
+	if output != "refusing" {
+		t.Errorf("expected only the first hook to run, got %q", output)
+	}
+}
+
+func TestRunner_PostHookFailuresDoNotStopOthers(t *testing.T) {
+	skipOnWindows(t)
+
+	r := NewRunner(config.Hooks{
+		PostSwitch: []config.Hook{
+			{Command: "exit 1"},
+			{Command: "echo still ran"},
+		},
+	})
+
+	output, err := r.Post(context.Background(), Event{})
+	if err == nil {
+		t.Error("expected an error from the failing hook")
+	}
+
+	if !strings.Contains(output, "still ran") {
+		t.Errorf("expected second hook output, got %q", output)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	ev := Event{
+		NewSubscriptionID:   "sub-id",
+		NewSubscriptionName: "Production",
+		NewTenantID:         "tenant-id",
+	}
+
+	tests := []struct {
+		name string
+		hook config.Hook
+		want bool
+	}{
+		{"global", config.Hook{}, true},
+		{"subscription id", config.Hook{Subscriptions: []string{"SUB-ID"}}, true},
+		{"subscription name", config.Hook{Subscriptions: []string{"production"}}, true},
+		{"tenant", config.Hook{Tenants: []string{"tenant-id"}}, true},
+		{"other subscription", config.Hook{Subscriptions: []string{"staging"}}, false},
+		{"other tenant", config.Hook{Tenants: []string{"other"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(&tt.hook, ev); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package kubeconfig reads and updates kubectl configuration files.
+package kubeconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrContextNotFound is returned when a context does not exist in the kubeconfig.
+var ErrContextNotFound = errors.New("kubeconfig context not found")
+
+// Context is a named kubeconfig context.
+type Context struct {
+	Name      string
+	Cluster   string
+	User      string
+	Namespace string
+}
+
+// File is a kubeconfig file. Fields azswitch does not use are preserved on save.
+type File struct {
+	path string
+	root yaml.Node
+}
+
+// DefaultPath returns the kubeconfig location kubectl would write to:
+// the first entry of KUBECONFIG, or ~/.kube/config.
+func DefaultPath() (string, error) {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		for _, p := range filepath.SplitList(env) {
+			if p != "" {
+				return p, nil
+			}
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Load reads the kubeconfig at path. A missing file yields an empty kubeconfig.
+func Load(path string) (*File, error) {
+	f := &File{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &f.root); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Path returns the file's location.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Contexts returns the contexts defined in the file.
+func (f *File) Contexts() []Context {
+	list := f.field("contexts")
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	contexts := make([]Context, 0, len(list.Content))
+	for _, item := range list.Content {
+		var raw struct {
+			Name    string `yaml:"name"`
+			Context struct {
+				Cluster   string `yaml:"cluster"`
+				User      string `yaml:"user"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"context"`
+		}
+		if err := item.Decode(&raw); err != nil {
+			continue
+		}
+		contexts = append(contexts, Context{
+			Name:      raw.Name,
+			Cluster:   raw.Context.Cluster,
+			User:      raw.Context.User,
+			Namespace: raw.Context.Namespace,
+		})
+	}
+
+	return contexts
+}
+
+// CurrentContext returns the name of the current context.
+func (f *File) CurrentContext() string {
+	if node := f.field("current-context"); node != nil {
+		return node.Value
+	}
+	return ""
+}
+
+// SetCurrentContext makes name the current context.
+func (f *File) SetCurrentContext(name string) error {
+	if _, ok := f.Context(name); !ok {
+		return fmt.Errorf("%w: %s", ErrContextNotFound, name)
+	}
+
+	if node := f.field("current-context"); node != nil {
+		node.Value = name
+		node.Tag = "!!str"
+		node.Style = 0
+		return nil
+	}
+
+	doc := f.document()
+	doc.Content = append(doc.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "current-context"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+	)
+	return nil
+}
+
+// Context returns the context with the given name.
+func (f *File) Context(name string) (Context, bool) {
+	for _, c := range f.Contexts() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Context{}, false
+}
+
+// FindContext returns the context matching an AKS cluster name, either by
+// context name or by the cluster it points at. Names are matched case-insensitively.
+func (f *File) FindContext(clusterName string) (Context, bool) {
+	contexts := f.Contexts()
+	for _, c := range contexts {
+		if strings.EqualFold(c.Name, clusterName) {
+			return c, true
+		}
+	}
+	for _, c := range contexts {
+		if strings.EqualFold(c.Cluster, clusterName) {
+			return c, true
+		}
+	}
+	return Context{}, false
+}
+
+// Save writes the file back to its path.
+func (f *File) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&f.root); err != nil {
+		return fmt.Errorf("failed to encode kubeconfig: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode kubeconfig: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	if err := os.WriteFile(f.path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	return nil
+}
+
+// document returns the top-level mapping, creating it if the file is empty.
+func (f *File) document() *yaml.Node {
+	if f.root.Kind == 0 {
+		f.root.Kind = yaml.DocumentNode
+	}
+	if len(f.root.Content) == 0 {
+		f.root.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	return f.root.Content[0]
+}
+
+// field returns the value node of a top-level key.
+func (f *File) field(name string) *yaml.Node {
+	if len(f.root.Content) == 0 {
+		return nil
+	}
+	doc := f.root.Content[0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == name {
+			return doc.Content[i+1]
+		}
+	}
+	return nil
+}
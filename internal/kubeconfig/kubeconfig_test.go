@@ -0,0 +1,140 @@
+package kubeconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// copyFixture copies a testdata file into a temporary directory.
+func copyFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Contexts(t *testing.T) {
+	f, err := Load(filepath.Join("testdata", "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	contexts := f.Contexts()
+	if len(contexts) != 2 {
+		t.Fatalf("expected 2 contexts, got %d", len(contexts))
+	}
+
+	if contexts[1].Name != "dev" || contexts[1].Cluster != "dev-aks" || contexts[1].Namespace != "team-a" {
+		t.Errorf("unexpected context: %+v", contexts[1])
+	}
+
+	if f.CurrentContext() != "prod-aks" {
+		t.Errorf("expected current context 'prod-aks', got '%s'", f.CurrentContext())
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	f, err := Load(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(f.Contexts()) != 0 || f.CurrentContext() != "" {
+		t.Error("expected empty kubeconfig")
+	}
+}
+
+func TestFindContext(t *testing.T) {
+	f, err := Load(filepath.Join("testdata", "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Matched by context name.
+	if c, ok := f.FindContext("PROD-AKS"); !ok || c.Name != "prod-aks" {
+		t.Errorf("expected prod-aks context, got %+v, %v", c, ok)
+	}
+
+	// Matched by cluster name.
+	if c, ok := f.FindContext("dev-aks"); !ok || c.Name != "dev" {
+		t.Errorf("expected dev context, got %+v, %v", c, ok)
+	}
+
+	if _, ok := f.FindContext("staging-aks"); ok {
+		t.Error("expected no match for unknown cluster")
+	}
+}
+
+func TestSetCurrentContext_RoundTrip(t *testing.T) {
+	path := copyFixture(t, "config.yaml")
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.SetCurrentContext("dev"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reloaded.CurrentContext() != "dev" {
+		t.Errorf("expected current context 'dev', got '%s'", reloaded.CurrentContext())
+	}
+
+	// Unrelated fields survive the rewrite.
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "token: dev-token") {
+		t.Error("expected users section to be preserved")
+	}
+}
+
+func TestSetCurrentContext_AddsMissingKey(t *testing.T) {
+	path := copyFixture(t, "no-current.yaml")
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.SetCurrentContext("dev"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, _ := Load(path)
+	if reloaded.CurrentContext() != "dev" {
+		t.Errorf("expected current context 'dev', got '%s'", reloaded.CurrentContext())
+	}
+}
+
+func TestSetCurrentContext_Unknown(t *testing.T) {
+	f, err := Load(filepath.Join("testdata", "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.SetCurrentContext("nope"); !errors.Is(err, ErrContextNotFound) {
+		t.Errorf("expected ErrContextNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package listing sorts and groups subscriptions for display, the same way
+// in the TUI and in CLI output.
+package listing
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/history"
+)
+
+// Group is a run of subscriptions sharing a tenant or cloud.
+type Group struct {
+	// Key identifies the group: a tenant ID or cloud name.
+	Key string
+
+	// Title is shown in the group header.
+	Title string
+
+	// Subscriptions are the group's members, in sort order.
+	Subscriptions []azure.Subscription
+}
+
+// Sort returns the subscriptions in the given order, one of config.Sorts.
+// Ties, and subscriptions without recorded use, are ordered by name. The
+// input is left unchanged.
+func Sort(subs []azure.Subscription, order string, h *history.History) []azure.Subscription {
+	sorted := slices.Clone(subs)
+	slices.SortStableFunc(sorted, func(a, b azure.Subscription) int {
+		var c int
+		switch order {
+		case config.SortTenant:
+			c = compareFold(TenantTitle(a), TenantTitle(b))
+		case config.SortState:
+			c = cmp.Compare(stateRank(a.State), stateRank(b.State))
+			if c == 0 {
+				c = compareFold(a.State, b.State)
+			}
+		case config.SortRecent:
+			c = h.Get(b.ID).LastUsed.Compare(h.Get(a.ID).LastUsed)
+		case config.SortUsage:
+			c = cmp.Compare(h.Get(b.ID).Count, h.Get(a.ID).Count)
+		}
+		if c == 0 {
+			c = compareFold(a.Name, b.Name)
+		}
+		return c
+	})
+	return sorted
+}
+
+// Groups splits sorted subscriptions by the given grouping, one of
+// config.Groups. Groups are ordered by title and keep the subscriptions'
+// order. Without grouping, all subscriptions form a single untitled group.
+func Groups(subs []azure.Subscription, grouping string) []Group {
+	if grouping != config.GroupTenant && grouping != config.GroupCloud && grouping != config.GroupUser {
+		return []Group{{Subscriptions: subs}}
+	}
+
+	var groups []Group
+	index := make(map[string]int)
+	for i := range subs {
+		key, title := subs[i].TenantID, TenantTitle(subs[i])
+		switch grouping {
+		case config.GroupCloud:
+			key, title = subs[i].CloudName, subs[i].CloudName
+			if title == "" {
+				title = "Unknown cloud"
+			}
+		case config.GroupUser:
+			key, title = strings.ToLower(subs[i].User.Name), subs[i].User.Name
+			if title == "" {
+				title = "Unknown user"
+			}
+		}
+
+		j, ok := index[key]
+		if !ok {
+			j = len(groups)
+			index[key] = j
+			groups = append(groups, Group{Key: key, Title: title})
+		}
+		groups[j].Subscriptions = append(groups[j].Subscriptions, subs[i])
+	}
+
+	slices.SortStableFunc(groups, func(a, b Group) int {
+		return compareFold(a.Title, b.Title)
+	})
+	return groups
+}
+
+// TenantTitle returns the display name of a subscription's tenant, or its ID
+// when az did not report one.
+func TenantTitle(sub azure.Subscription) string {
+	if sub.TenantDisplayName != "" {
+		return sub.TenantDisplayName
+	}
+	return sub.TenantID
+}
+
+// stateRank orders enabled subscriptions before all others.
+func stateRank(state string) int {
+	if strings.EqualFold(state, "Enabled") {
+		return 0
+	}
+	return 1
+}
+
+// compareFold compares strings ignoring case.
+func compareFold(a, b string) int {
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
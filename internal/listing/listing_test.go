@@ -0,0 +1,89 @@
+package listing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/history"
+)
+
+var subs = []azure.Subscription{
+	{ID: "1", Name: "delta", TenantID: "t2", TenantDisplayName: "Beta Corp", State: "Enabled", CloudName: "AzureCloud", User: azure.User{Name: "admin@acme.com"}},
+	{ID: "2", Name: "Alpha", TenantID: "t1", TenantDisplayName: "Acme", State: "Disabled", CloudName: "AzureCloud"},
+	{ID: "3", Name: "charlie", TenantID: "t2", TenantDisplayName: "Beta Corp", State: "Enabled", CloudName: "AzureUSGovernment"},
+	{ID: "4", Name: "bravo", TenantID: "t1", TenantDisplayName: "Acme", State: "Warned", CloudName: "AzureCloud"},
+}
+
+func names(subs []azure.Subscription) string {
+	n := make([]string, len(subs))
+	for i := range subs {
+		n[i] = subs[i].Name
+	}
+	return strings.Join(n, ",")
+}
+
+func TestSort(t *testing.T) {
+	var h history.History
+	now := time.Now()
+	h.Add("3", now.Add(-time.Hour))
+	h.Add("3", now.Add(-time.Hour))
+	h.Add("4", now)
+
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{config.SortName, "Alpha,bravo,charlie,delta"},
+		{config.SortTenant, "Alpha,bravo,charlie,delta"},
+		{config.SortState, "charlie,delta,Alpha,bravo"},
+		{config.SortRecent, "bravo,charlie,Alpha,delta"},
+		{config.SortUsage, "charlie,bravo,Alpha,delta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			if got := names(Sort(subs, tt.order, &h)); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if names(subs) != "delta,Alpha,charlie,bravo" {
+		t.Error("expected the input to be left unchanged")
+	}
+}
+
+func TestSort_WithoutHistory(t *testing.T) {
+	if got := names(Sort(subs, config.SortRecent, nil)); got != "Alpha,bravo,charlie,delta" {
+		t.Errorf("expected name order without history, got %s", got)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	sorted := Sort(subs, config.SortName, nil)
+
+	groups := Groups(sorted, config.GroupTenant)
+	if len(groups) != 2 || groups[0].Title != "Acme" || groups[1].Key != "t2" {
+		t.Fatalf("expected Acme and Beta Corp groups, got %+v", groups)
+	}
+	if names(groups[0].Subscriptions) != "Alpha,bravo" || names(groups[1].Subscriptions) != "charlie,delta" {
+		t.Errorf("expected groups to keep the sort order, got %+v", groups)
+	}
+
+	groups = Groups(sorted, config.GroupCloud)
+	if len(groups) != 2 || groups[0].Title != "AzureCloud" || names(groups[1].Subscriptions) != "charlie" {
+		t.Errorf("expected groups per cloud, got %+v", groups)
+	}
+
+	groups = Groups(sorted, config.GroupUser)
+	if len(groups) != 2 || groups[0].Title != "admin@acme.com" || groups[1].Title != "Unknown user" || names(groups[1].Subscriptions) != "Alpha,bravo,charlie" {
+		t.Errorf("expected groups per user, got %+v", groups)
+	}
+
+	groups = Groups(sorted, config.GroupNone)
+	if len(groups) != 1 || groups[0].Title != "" || len(groups[0].Subscriptions) != 4 {
+		t.Errorf("expected a single untitled group, got %+v", groups)
+	}
+}
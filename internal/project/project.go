@@ -0,0 +1,143 @@
+// Package project reads .azswitch files, which pin a directory tree to an
+// Azure subscription, tenant or cloud.
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// FileName is the name of the per-directory azswitch file.
+const FileName = ".azswitch"
+
+// Errors returned when resolving a project file.
+var (
+	ErrNotFound      = errors.New("no .azswitch file found")
+	ErrEmpty         = errors.New(".azswitch file sets neither subscription nor tenant")
+	ErrNoMatch       = errors.New("no subscription matches .azswitch file")
+	ErrAmbiguous     = errors.New("several subscriptions match .azswitch file")
+	ErrCloudMismatch = errors.New("active Azure cloud does not match .azswitch file")
+)
+
+// File is a parsed .azswitch file.
+type File struct {
+	// Subscription is a subscription ID or name.
+	Subscription string `yaml:"subscription"`
+
+	// Tenant is a tenant ID.
+	Tenant string `yaml:"tenant"`
+
+	// Cloud is an Azure cloud name such as AzureCloud or AzureUSGovernment.
+	Cloud string `yaml:"cloud"`
+
+	// Path is where the file was read from.
+	Path string `yaml:"-"`
+}
+
+// Find looks for a .azswitch file in dir and its parents.
+func Find(dir string) (*File, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+// Load reads a .azswitch file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	f := &File{Path: path}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if f.Subscription == "" && f.Tenant == "" {
+		return nil, fmt.Errorf("%s: %w", path, ErrEmpty)
+	}
+
+	return f, nil
+}
+
+// Matches reports whether the subscription satisfies the file.
+func (f *File) Matches(sub *azure.Subscription) bool {
+	if f.Subscription != "" && !strings.EqualFold(f.Subscription, sub.ID) && !strings.EqualFold(f.Subscription, sub.Name) {
+		return false
+	}
+	if f.Tenant != "" && !strings.EqualFold(f.Tenant, sub.TenantID) {
+		return false
+	}
+	if f.Cloud != "" && sub.CloudName != "" && !strings.EqualFold(f.Cloud, sub.CloudName) {
+		return false
+	}
+	return true
+}
+
+// MatchesAccount reports whether the active account satisfies the file.
+func (f *File) MatchesAccount(account *azure.Account) bool {
+	return f.Matches(&azure.Subscription{
+		ID:        account.ID,
+		Name:      account.Name,
+		TenantID:  account.TenantID,
+		CloudName: account.EnvironmentName,
+	})
+}
+
+// CheckCloud returns ErrCloudMismatch if the active cloud differs from the file's.
+func (f *File) CheckCloud(account *azure.Account) error {
+	if f.Cloud != "" && account.EnvironmentName != "" && !strings.EqualFold(f.Cloud, account.EnvironmentName) {
+		return fmt.Errorf("%w: active cloud is %s, %s wants %s (run: az cloud set --name %s)",
+			ErrCloudMismatch, account.EnvironmentName, f.Path, f.Cloud, f.Cloud)
+	}
+	return nil
+}
+
+// Resolve picks the subscription the file refers to. When the file names only
+// a tenant, the first enabled subscription in that tenant is used.
+func (f *File) Resolve(subs []azure.Subscription) (azure.Subscription, error) {
+	var matches []azure.Subscription
+	for i := range subs {
+		if f.Matches(&subs[i]) {
+			matches = append(matches, subs[i])
+		}
+	}
+
+	if len(matches) == 0 {
+		return azure.Subscription{}, fmt.Errorf("%w: %s", ErrNoMatch, f.Path)
+	}
+
+	if f.Subscription == "" {
+		for i := range matches {
+			if strings.EqualFold(matches[i].State, "Enabled") {
+				return matches[i], nil
+			}
+		}
+		return matches[0], nil
+	}
+
+	if len(matches) > 1 {
+		return azure.Subscription{}, fmt.Errorf("%w: %s; use a subscription ID", ErrAmbiguous, f.Path)
+	}
+	return matches[0], nil
+}
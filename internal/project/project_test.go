@@ -0,0 +1,105 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+func writeFile(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind_WalksUp(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "subscription: Production\ntenant: tenant-a\n")
+
+	nested := filepath.Join(root, "infra", "modules")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Find(nested)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if f.Subscription != "Production" || f.Tenant != "tenant-a" {
+		t.Errorf("unexpected file: %+v", f)
+	}
+
+	if f.Path != filepath.Join(root, FileName) {
+		t.Errorf("unexpected path %s", f.Path)
+	}
+}
+
+func TestFind_NotFound(t *testing.T) {
+	if _, err := Find(t.TempDir()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "cloud: AzureCloud\n")
+
+	if _, err := Load(filepath.Join(dir, FileName)); !errors.Is(err, ErrEmpty) {
+		t.Errorf("expected ErrEmpty, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	subs := []azure.Subscription{
+		{Name: "Production", ID: "prod-a", TenantID: "tenant-a", State: "Enabled"},
+		{Name: "Production", ID: "prod-b", TenantID: "tenant-b", State: "Enabled"},
+		{Name: "Legacy", ID: "legacy-b", TenantID: "tenant-b", State: "Disabled"},
+	}
+
+	tests := []struct {
+		name    string
+		file    File
+		wantID  string
+		wantErr error
+	}{
+		{"by id", File{Subscription: "PROD-B"}, "prod-b", nil},
+		{"by name and tenant", File{Subscription: "production", Tenant: "tenant-a"}, "prod-a", nil},
+		{"ambiguous name", File{Subscription: "Production"}, "", ErrAmbiguous},
+		{"tenant only prefers enabled", File{Tenant: "tenant-b"}, "prod-b", nil},
+		{"no match", File{Subscription: "Staging"}, "", ErrNoMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := tt.file.Resolve(subs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if sub.ID != tt.wantID {
+				t.Errorf("expected %s, got %s", tt.wantID, sub.ID)
+			}
+		})
+	}
+}
+
+func TestMatchesAccountAndCloud(t *testing.T) {
+	f := &File{Subscription: "prod-a", Cloud: "AzureCloud", Path: FileName}
+
+	account := &azure.Account{ID: "prod-a", EnvironmentName: "AzureCloud"}
+	if !f.MatchesAccount(account) {
+		t.Error("expected account to match")
+	}
+
+	account.EnvironmentName = "AzureUSGovernment"
+	if f.MatchesAccount(account) {
+		t.Error("expected account in another cloud not to match")
+	}
+	if err := f.CheckCloud(account); !errors.Is(err, ErrCloudMismatch) {
+		t.Errorf("expected ErrCloudMismatch, got %v", err)
+	}
+}
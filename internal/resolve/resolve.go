@@ -0,0 +1,139 @@
+// Package resolve finds the subscription a query on the command line refers
+// to: an alias, an ID or ID prefix, part of a name, or a tenant's domain.
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// Errors returned when resolving a query.
+var (
+	ErrNotFound  = errors.New("no subscription matches")
+	ErrAmbiguous = errors.New("several subscriptions match")
+)
+
+// MinPrefix is the length from which a query is matched as an ID prefix, so
+// that short queries only match names.
+const MinPrefix = 4
+
+// AmbiguousError is returned when a query matches several subscriptions.
+type AmbiguousError struct {
+	Query      string
+	Candidates []azure.Subscription
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("%q matches %d subscriptions", e.Query, len(e.Candidates))
+}
+
+// Unwrap makes errors.Is match ErrAmbiguous.
+func (e *AmbiguousError) Unwrap() error {
+	return ErrAmbiguous
+}
+
+// Source is what queries are resolved against.
+type Source struct {
+	Subscriptions []azure.Subscription
+
+	// Aliases maps short names to subscription IDs or names, as in the
+	// config file.
+	Aliases map[string]string
+
+	// Tenants lists the tenants, whose domains are matched when nothing else
+	// is. Listing tenants is slow, so it is only called then. Optional.
+	Tenants func() ([]azure.Tenant, error)
+}
+
+// Candidates returns the subscriptions a query may refer to. Kinds of match
+// are tried from the most specific to the least: the exact ID or name, an ID
+// prefix, part of a name, and a tenant's default or verified domain. The
+// first kind with a match wins. An alias is replaced by its target first.
+func (s Source) Candidates(query string) ([]azure.Subscription, error) {
+	if target, ok := s.Aliases[query]; ok {
+		query = target
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	lower := strings.ToLower(query)
+	kinds := []func(sub *azure.Subscription) bool{
+		func(sub *azure.Subscription) bool {
+			return strings.EqualFold(sub.ID, query) || strings.EqualFold(sub.Name, query)
+		},
+		func(sub *azure.Subscription) bool {
+			return len(query) >= MinPrefix && strings.HasPrefix(strings.ToLower(sub.ID), lower)
+		},
+		func(sub *azure.Subscription) bool {
+			return strings.Contains(strings.ToLower(sub.Name), lower)
+		},
+	}
+	for _, matches := range kinds {
+		if found := s.filter(matches); len(found) > 0 {
+			return found, nil
+		}
+	}
+
+	if s.Tenants == nil {
+		return nil, nil
+	}
+	tenants, err := s.Tenants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	inDomain := make(map[string]bool)
+	for i := range tenants {
+		if hasDomain(&tenants[i], query) {
+			inDomain[strings.ToLower(tenants[i].TenantID)] = true
+		}
+	}
+	return s.filter(func(sub *azure.Subscription) bool {
+		return inDomain[strings.ToLower(sub.TenantID)]
+	}), nil
+}
+
+// Resolve returns the one subscription a query refers to. It fails with
+// ErrNotFound, or with an *AmbiguousError listing the candidates.
+func (s Source) Resolve(query string) (azure.Subscription, error) {
+	candidates, err := s.Candidates(query)
+	if err != nil {
+		return azure.Subscription{}, err
+	}
+
+	switch len(candidates) {
+	case 0:
+		return azure.Subscription{}, fmt.Errorf("%w %q", ErrNotFound, query)
+	case 1:
+		return candidates[0], nil
+	}
+	return azure.Subscription{}, &AmbiguousError{Query: query, Candidates: candidates}
+}
+
+// filter returns the subscriptions for which matches is true.
+func (s Source) filter(matches func(sub *azure.Subscription) bool) []azure.Subscription {
+	var found []azure.Subscription
+	for i := range s.Subscriptions {
+		if matches(&s.Subscriptions[i]) {
+			found = append(found, s.Subscriptions[i])
+		}
+	}
+	return found
+}
+
+// hasDomain reports whether domain is the tenant's default or a verified domain.
+func hasDomain(t *azure.Tenant, domain string) bool {
+	if strings.EqualFold(t.DefaultDomain, domain) {
+		return true
+	}
+	for _, d := range t.Domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
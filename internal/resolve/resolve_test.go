@@ -0,0 +1,86 @@
+package resolve
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+var subs = []azure.Subscription{
+	{Name: "Contoso Prod", ID: "1a2b3c4d-0000-0000-0000-000000000001", TenantID: "t1"},
+	{Name: "Contoso Dev", ID: "1a2b9999-0000-0000-0000-000000000002", TenantID: "t1"},
+	{Name: "Fabrikam Prod", ID: "5e6f7a8b-0000-0000-0000-000000000003", TenantID: "t2"},
+	{Name: "prod", ID: "9c8d7e6f-0000-0000-0000-000000000004", TenantID: "t3"},
+}
+
+func names(subs []azure.Subscription) string {
+	n := make([]string, len(subs))
+	for i := range subs {
+		n[i] = subs[i].Name
+	}
+	return strings.Join(n, ",")
+}
+
+func TestSource_Candidates(t *testing.T) {
+	tenantCalls := 0
+	source := Source{
+		Subscriptions: subs,
+		Aliases:       map[string]string{"fab": "Fabrikam Prod"},
+		Tenants: func() ([]azure.Tenant, error) {
+			tenantCalls++
+			return []azure.Tenant{
+				{TenantID: "t1", DefaultDomain: "contoso.onmicrosoft.com", Domains: []string{"contoso.com"}},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"PROD", "prod"}, // an exact name beats parts of names
+		{"1a2b3c4d-0000-0000-0000-000000000001", "Contoso Prod"},
+		{"5e6f", "Fabrikam Prod"},            // a unique ID prefix
+		{"1a2b", "Contoso Prod,Contoso Dev"}, // a shared ID prefix
+		{"1a2", ""},                          // too short for a prefix
+		{"contoso", "Contoso Prod,Contoso Dev"},
+		{"fab", "Fabrikam Prod"}, // an alias
+		{"Contoso.com", "Contoso Prod,Contoso Dev"},
+		{"nothing", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := source.Candidates(tt.query)
+		if err != nil {
+			t.Fatalf("Candidates(%q): unexpected error: %v", tt.query, err)
+		}
+		if names(got) != tt.want {
+			t.Errorf("Candidates(%q) = %s, want %s", tt.query, names(got), tt.want)
+		}
+	}
+
+	// Tenants are only listed when no subscription matches otherwise.
+	if tenantCalls != 3 {
+		t.Errorf("expected tenants to be listed 3 times, got %d", tenantCalls)
+	}
+}
+
+func TestSource_Resolve(t *testing.T) {
+	source := Source{Subscriptions: subs}
+
+	if sub, err := source.Resolve("fabrikam"); err != nil || sub.Name != "Fabrikam Prod" {
+		t.Errorf("expected Fabrikam Prod, got %v, %v", sub.Name, err)
+	}
+
+	_, err := source.Resolve("contoso")
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) || !errors.Is(err, ErrAmbiguous) || len(ambiguous.Candidates) != 2 {
+		t.Errorf("expected an ambiguous match, got %v", err)
+	}
+
+	if _, err := source.Resolve("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
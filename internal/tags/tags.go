@@ -0,0 +1,223 @@
+// Package tags enriches subscriptions with their ARM tags, which az account
+// list does not return, and caches them between runs.
+package tags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// EnvPath is the environment variable that overrides the cache file location.
+const EnvPath = "AZSWITCH_TAGS"
+
+// DefaultTTL is how long fetched tags are used before they are fetched again.
+const DefaultTTL = 24 * time.Hour
+
+// parallel is the number of subscriptions fetched at once.
+const parallel = 4
+
+// Options controls which tags are fetched.
+type Options struct {
+	// TTL is how long fetched tags are used. Zero means DefaultTTL.
+	TTL time.Duration
+
+	// Force fetches every subscription's tags, however recent.
+	Force bool
+}
+
+// Entry is the cached tags of one subscription.
+type Entry struct {
+	// Tags are the subscription's tags. A subscription without tags has none.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// FetchedAt is when the tags were fetched.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Cache maps subscription IDs to their cached tags. The zero Cache is empty
+// and ready to use.
+type Cache struct {
+	Subscriptions map[string]Entry `json:"subscriptions"`
+}
+
+// DefaultPath returns the cache file location under the user cache
+// directory, honoring AZSWITCH_TAGS.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cache, "azswitch", "tags.json"), nil
+}
+
+// Load reads the cache at path. A missing file yields an empty cache.
+func Load(path string) (*Cache, error) {
+	c := &Cache{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to read tags cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the cache to path, creating its directory if needed.
+func (c *Cache) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tags cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create tags cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write tags cache: %w", err)
+	}
+	return nil
+}
+
+// Put records the tags of a subscription, fetched at the given time.
+func (c *Cache) Put(id string, tags map[string]string, at time.Time) {
+	if c.Subscriptions == nil {
+		c.Subscriptions = make(map[string]Entry)
+	}
+	c.Subscriptions[id] = Entry{Tags: tags, FetchedAt: at}
+}
+
+// Stale returns the subscriptions whose tags were never fetched or were
+// fetched more than ttl before now.
+func (c *Cache) Stale(subs []azure.Subscription, ttl time.Duration, now time.Time) []azure.Subscription {
+	var stale []azure.Subscription
+	for i := range subs {
+		e, ok := c.Subscriptions[subs[i].ID]
+		if !ok || now.Sub(e.FetchedAt) > ttl {
+			stale = append(stale, subs[i])
+		}
+	}
+	return stale
+}
+
+// Apply sets the cached tags of the subscriptions, however old.
+func (c *Cache) Apply(subs []azure.Subscription) {
+	for i := range subs {
+		if e, ok := c.Subscriptions[subs[i].ID]; ok {
+			subs[i].Tags = e.Tags
+		}
+	}
+}
+
+// Refresh fetches the tags of the stale subscriptions, or of all of them
+// with opts.Force, and caches them. It returns the number of subscriptions
+// whose tags were stored. Failures are joined in the error; those
+// subscriptions keep their cached tags and are fetched again next time.
+func (c *Cache) Refresh(ctx context.Context, client azure.Client, subs []azure.Subscription, opts Options, now time.Time) (int, error) {
+	stale := subs
+	if !opts.Force {
+		ttl := opts.TTL
+		if ttl == 0 {
+			ttl = DefaultTTL
+		}
+		stale = c.Stale(subs, ttl, now)
+	}
+
+	fetched := make([]map[string]string, len(stale))
+	errs := make([]error, len(stale))
+	sem := make(chan struct{}, parallel)
+
+	var wg sync.WaitGroup
+	for i := range stale {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			tags, err := client.GetSubscriptionTags(ctx, stale[i].ID)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", stale[i].Name, err)
+				return
+			}
+			fetched[i] = tags
+		}()
+	}
+	wg.Wait()
+
+	stored := 0
+	for i := range stale {
+		if errs[i] == nil {
+			c.Put(stale[i].ID, fetched[i], now)
+			stored++
+		}
+	}
+	return stored, errors.Join(errs...)
+}
+
+// Enrich sets the tags of the subscriptions from the cache file at path,
+// refreshing them first as opts say. The cache file is only rewritten when
+// tags were fetched. The subscriptions are enriched even when some fetches
+// fail.
+func Enrich(ctx context.Context, client azure.Client, path string, subs []azure.Subscription, opts Options) error {
+	c, err := Load(path)
+	if err != nil {
+		return err
+	}
+	stored, refreshErr := c.Refresh(ctx, client, subs, opts, time.Now())
+	c.Apply(subs)
+
+	if stored == 0 {
+		return refreshErr
+	}
+	return errors.Join(refreshErr, c.Save(path))
+}
+
+// Format renders tags as "name=value" pairs. With names, only those tags are
+// rendered, in that order; otherwise all are, sorted by name.
+func Format(tags map[string]string, names []string) []string {
+	if len(names) == 0 {
+		names = slices.Sorted(maps.Keys(tags))
+	}
+
+	var pairs []string
+	for _, name := range names {
+		if value, ok := Lookup(tags, name); ok {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	return pairs
+}
+
+// Lookup returns the value of a tag, matching its name ignoring case as ARM
+// does.
+func Lookup(tags map[string]string, name string) (string, bool) {
+	if value, ok := tags[name]; ok {
+		return value, true
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,109 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// tagClient returns a mock client that tags each subscription with its ID.
+// Fetching "broken" fails.
+func tagClient() *azure.MockClient {
+	client := azure.NewMockClient()
+	client.GetSubscriptionTagsFunc = func(_ context.Context, id string) (map[string]string, error) {
+		if id == "broken" {
+			return nil, fmt.Errorf("%w: AuthorizationFailed", azure.ErrCommandFailed)
+		}
+		return map[string]string{"env": "env-" + id}, nil
+	}
+	return client
+}
+
+func TestRefresh_FetchesStale(t *testing.T) {
+	client := tagClient()
+	c := &Cache{}
+	c.Put("fresh", map[string]string{"env": "cached"}, now.Add(-time.Hour))
+	c.Put("old", map[string]string{"env": "cached"}, now.Add(-2*DefaultTTL))
+
+	subs := []azure.Subscription{{ID: "fresh"}, {ID: "old"}, {ID: "new"}, {ID: "broken", Name: "Broken"}}
+	stored, err := c.Refresh(context.Background(), client, subs, Options{}, now)
+	if stored != 2 {
+		t.Errorf("expected the tags of 2 subscriptions to be stored, got %d", stored)
+	}
+	if err == nil || !strings.Contains(err.Error(), "Broken") {
+		t.Errorf("expected the broken subscription to fail, got %v", err)
+	}
+	if got := strings.Join(client.Calls.GetSubscriptionTags, ","); len(client.Calls.GetSubscriptionTags) != 3 || strings.Contains(got, "fresh") {
+		t.Errorf("expected only stale subscriptions to be fetched, got %s", got)
+	}
+
+	c.Apply(subs)
+	want := []string{"cached", "env-old", "env-new", ""}
+	for i := range subs {
+		if subs[i].Tags["env"] != want[i] {
+			t.Errorf("expected %s to have env %q, got %v", subs[i].ID, want[i], subs[i].Tags)
+		}
+	}
+	if len(c.Stale(subs, DefaultTTL, now)) != 1 {
+		t.Errorf("expected only the broken subscription to stay stale, got %v", c.Stale(subs, DefaultTTL, now))
+	}
+}
+
+func TestEnrich_UsesCacheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azswitch", "tags.json")
+	client := tagClient()
+
+	subs := []azure.Subscription{{ID: "a"}}
+	if err := Enrich(context.Background(), client, path, subs, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	saved, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected the cache to be saved: %v", err)
+	}
+
+	// Nothing is stale, so nothing is fetched and the file is left alone.
+	if err := os.Chtimes(path, time.Time{}, saved.ModTime().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	again := []azure.Subscription{{ID: "a"}}
+	if err := Enrich(context.Background(), client, path, again, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again[0].Tags["env"] != "env-a" {
+		t.Errorf("expected cached tags, got %v", again[0].Tags)
+	}
+	if len(client.Calls.GetSubscriptionTags) != 1 {
+		t.Errorf("expected one fetch, got %v", client.Calls.GetSubscriptionTags)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(saved.ModTime().Add(-time.Hour)) {
+		t.Error("expected the cache file not to be rewritten without fetches")
+	}
+
+	// Force fetches fresh tags too.
+	if err := Enrich(context.Background(), client, path, again, Options{Force: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.Calls.GetSubscriptionTags) != 2 {
+		t.Errorf("expected a forced fetch, got %v", client.Calls.GetSubscriptionTags)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tags := map[string]string{"Env": "prod", "owner": "team-a", "cost-center": "42"}
+
+	if got := strings.Join(Format(tags, nil), " "); got != "Env=prod cost-center=42 owner=team-a" {
+		t.Errorf("expected all tags sorted, got %q", got)
+	}
+	if got := strings.Join(Format(tags, []string{"env", "missing", "owner"}), " "); got != "env=prod owner=team-a" {
+		t.Errorf("expected the named tags in order, got %q", got)
+	}
+}
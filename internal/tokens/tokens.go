@@ -0,0 +1,127 @@
+// Package tokens reports whether the Azure CLI still holds a usable token
+// for each tenant.
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// ExpiringWithin is how close to its expiry a token is reported as expiring.
+const ExpiringWithin = 10 * time.Minute
+
+// parallel is the number of tenants checked at once.
+const parallel = 4
+
+// State is the validity of a tenant's token.
+type State string
+
+// Token states.
+const (
+	// Valid means a token was acquired without a login.
+	Valid State = "valid"
+
+	// Expiring means the token expires within ExpiringWithin and the Azure
+	// CLI did not refresh it.
+	Expiring State = "expiring"
+
+	// Expired means only an interactive login would give a token.
+	Expired State = "expired"
+
+	// Unknown means the check failed for another reason.
+	Unknown State = "unknown"
+)
+
+// Status is the token status of a tenant.
+type Status struct {
+	TenantID  string    `json:"tenantId"`
+	State     State     `json:"state"`
+	ExpiresOn time.Time `json:"expiresOn,omitzero"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NeedsLogin reports whether the tenant needs an interactive login.
+func (s Status) NeedsLogin() bool {
+	return s.State == Expired
+}
+
+// Label describes the status for display, relative to now.
+func (s Status) Label(now time.Time) string {
+	switch s.State {
+	case Valid:
+		return "valid, expires in " + Remaining(s.ExpiresOn, now)
+	case Expiring:
+		return "expiring in " + Remaining(s.ExpiresOn, now)
+	case Expired:
+		return "login required"
+	}
+	if s.Error != "" {
+		return "unknown: " + s.Error
+	}
+	return "unknown"
+}
+
+// Check acquires a token for a tenant, which the Azure CLI refreshes silently
+// if it can, and reports its status.
+func Check(ctx context.Context, client azure.Client, tenantID string, now time.Time) Status {
+	status := Status{TenantID: tenantID}
+
+	token, err := client.GetAccessToken(ctx, tenantID)
+	switch {
+	case errors.Is(err, azure.ErrAuthRequired):
+		status.State = Expired
+		return status
+	case err != nil:
+		status.State = Unknown
+		status.Error = err.Error()
+		return status
+	}
+
+	status.ExpiresOn = token.Expiry()
+	switch {
+	case !status.ExpiresOn.After(now):
+		status.State = Expired
+	case status.ExpiresOn.Sub(now) <= ExpiringWithin:
+		status.State = Expiring
+	default:
+		status.State = Valid
+	}
+	return status
+}
+
+// CheckAll checks the tokens of several tenants at once. The statuses are in
+// the order of tenantIDs.
+func CheckAll(ctx context.Context, client azure.Client, tenantIDs []string, now time.Time) []Status {
+	statuses := make([]Status, len(tenantIDs))
+	sem := make(chan struct{}, parallel)
+
+	var wg sync.WaitGroup
+	for i, id := range tenantIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			statuses[i] = Check(ctx, client, id, now)
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// Remaining formats the time left until t, rounded to minutes.
+func Remaining(t, now time.Time) string {
+	d := t.Sub(now).Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
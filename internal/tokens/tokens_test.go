@@ -0,0 +1,71 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// tokenClient returns a mock client whose tokens expire after the given
+// durations, per tenant. Tenants without one need a login.
+func tokenClient(expiries map[string]time.Duration) *azure.MockClient {
+	client := azure.NewMockClient()
+	client.GetAccessTokenFunc = func(_ context.Context, tenantID string) (*azure.AccessToken, error) {
+		if tenantID == "broken" {
+			return nil, fmt.Errorf("%w: connection reset", azure.ErrCommandFailed)
+		}
+		d, ok := expiries[tenantID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %w: AADSTS700082", azure.ErrCommandFailed, azure.ErrAuthRequired)
+		}
+		return &azure.AccessToken{Tenant: tenantID, ExpiresOn: now.Add(d).Unix()}, nil
+	}
+	return client
+}
+
+func TestCheckAll(t *testing.T) {
+	client := tokenClient(map[string]time.Duration{
+		"valid":    time.Hour,
+		"expiring": 5 * time.Minute,
+		"past":     -time.Minute,
+	})
+
+	ids := []string{"valid", "expiring", "past", "login", "broken"}
+	want := []State{Valid, Expiring, Expired, Expired, Unknown}
+
+	statuses := CheckAll(context.Background(), client, ids, now)
+	for i, status := range statuses {
+		if status.TenantID != ids[i] || status.State != want[i] {
+			t.Errorf("expected %s to be %s, got %+v", ids[i], want[i], status)
+		}
+	}
+	if len(client.Calls.GetAccessToken) != len(ids) {
+		t.Errorf("expected one token request per tenant, got %v", client.Calls.GetAccessToken)
+	}
+	if !statuses[3].NeedsLogin() || statuses[4].NeedsLogin() {
+		t.Error("expected only expired tokens to need a login")
+	}
+}
+
+func TestStatus_Label(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   string
+	}{
+		{Status{State: Valid, ExpiresOn: now.Add(75 * time.Minute)}, "valid, expires in 1h15m"},
+		{Status{State: Expiring, ExpiresOn: now.Add(4 * time.Minute)}, "expiring in 4m"},
+		{Status{State: Expired}, "login required"},
+		{Status{State: Unknown, Error: "boom"}, "unknown: boom"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.Label(now); got != tt.want {
+			t.Errorf("Label() = %q, want %q", got, tt.want)
+		}
+	}
+}
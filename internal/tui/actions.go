@@ -0,0 +1,338 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/batch"
+	"github.com/l2D/azswitch/internal/config"
+)
+
+// actionKind is what an entry of the actions menu does.
+type actionKind int
+
+const (
+	actionCopy actionKind = iota
+	actionExport
+	actionFavorites
+	actionCommand
+	actionRemove
+	actionSwitchUser
+	actionClearMarks
+)
+
+// action is an entry of the actions menu.
+type action struct {
+	label   string
+	kind    actionKind
+	command config.Command
+	user    azure.User
+}
+
+// defaultExportFile is suggested by the export prompt.
+const defaultExportFile = "subscriptions.txt"
+
+// openActions shows the actions menu for the marked subscriptions.
+func (m *Model) openActions() {
+	if len(m.targets()) == 0 {
+		return
+	}
+
+	m.actions = []action{
+		{label: "Copy IDs", kind: actionCopy},
+		{label: "Export to file", kind: actionExport},
+		{label: "Add to favorites", kind: actionFavorites},
+	}
+	for _, c := range m.commands {
+		m.actions = append(m.actions, action{label: "Run: " + c.Name, kind: actionCommand, command: c})
+	}
+	if !m.readOnly {
+		m.actions = append(m.actions, action{label: "Remove from Azure CLI profile", kind: actionRemove})
+		if targets := m.targets(); len(targets) == 1 {
+			for _, user := range azure.Users(m.allSubscriptions) {
+				if !strings.EqualFold(user.Name, targets[0].User.Name) {
+					m.actions = append(m.actions, action{label: "Switch user: " + user.Name, kind: actionSwitchUser, user: user})
+				}
+			}
+		}
+	}
+	if len(m.marks) > 0 {
+		m.actions = append(m.actions, action{label: "Clear marks", kind: actionClearMarks})
+	}
+
+	m.actionCursor = 0
+	m.setView(ViewActions)
+}
+
+// handleActionsKey handles keys in the actions menu, which works in
+// read-only mode too since no action switches anything.
+func (m Model) handleActionsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.setView(ViewSubscriptions)
+	case key.Matches(msg, m.keys.Up):
+		m.moveCursor(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.moveCursor(1)
+	case matchesKeys(msg, m.keys.Select):
+		return m.runAction(m.actions[m.actionCursor])
+	}
+	return m, nil
+}
+
+// matchesKeys is key.Matches, but also for a disabled binding.
+func matchesKeys(msg tea.KeyMsg, b key.Binding) bool {
+	for _, k := range b.Keys() {
+		if msg.String() == k {
+			return true
+		}
+	}
+	return false
+}
+
+// runAction runs an entry of the actions menu on the targets.
+func (m Model) runAction(a action) (tea.Model, tea.Cmd) {
+	subs := m.targets()
+	m.clearStatus()
+
+	switch a.kind {
+	case actionCopy:
+		ids := make([]string, len(subs))
+		for i := range subs {
+			ids[i] = subs[i].ID
+		}
+		m.copyText(strings.Join(ids, "\n"))
+		m.message = fmt.Sprintf("Copied %s to the clipboard", countSubscriptions(len(subs), "ID"))
+
+	case actionExport:
+		return m, m.startInput(inputExport, "Export to: ", defaultExportFile)
+
+	case actionFavorites:
+		return m.addFavorites(subs)
+
+	case actionCommand:
+		m.setView(ViewSubscriptions)
+		m.state = StateSwitching
+		m.activity = fmt.Sprintf("Running %s in %s...", a.command.Name, countSubscriptions(len(subs), ""))
+		return m, tea.Batch(m.spinner.Tick, runCommand(a.command, subs))
+
+	case actionRemove:
+		prompt := fmt.Sprintf("Remove %s from the Azure CLI profile? (y/N): ", countSubscriptions(len(subs), ""))
+		return m, m.startInput(inputRemove, prompt, "")
+
+	case actionSwitchUser:
+		m.setView(ViewSubscriptions)
+		m.state = StateSwitching
+		return m, tea.Batch(m.spinner.Tick, m.switchUser(subs[0], a.user))
+
+	case actionClearMarks:
+		m.marks = make(map[string]bool)
+		m.message = "Marks cleared"
+	}
+
+	m.setView(ViewSubscriptions)
+	return m, nil
+}
+
+// targets returns the subscriptions actions apply to: the marked ones, or
+// the one under the cursor if none are marked.
+func (m Model) targets() []azure.Subscription {
+	if marked := m.markedSubscriptions(); len(marked) > 0 {
+		return marked
+	}
+	if sub := m.selectedSubscription(); sub != nil {
+		return []azure.Subscription{*sub}
+	}
+	return nil
+}
+
+// markedSubscriptions returns the marked subscriptions, including those
+// hidden by the filter.
+func (m Model) markedSubscriptions() []azure.Subscription {
+	var marked []azure.Subscription
+	for i := range m.allSubscriptions {
+		if m.marks[m.allSubscriptions[i].ID] {
+			marked = append(marked, m.allSubscriptions[i])
+		}
+	}
+	return marked
+}
+
+// export writes the targets to a file: JSON for a .json file, otherwise one
+// ID per line.
+func (m Model) export(path string) (tea.Model, tea.Cmd) {
+	subs := m.targets()
+	m.setView(ViewSubscriptions)
+	if strings.TrimSpace(path) == "" {
+		return m, nil
+	}
+
+	return m, func() tea.Msg {
+		var data []byte
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			var err error
+			if data, err = json.MarshalIndent(subs, "", "  "); err != nil {
+				return actionDoneMsg{err: fmt.Errorf("failed to encode subscriptions: %w", err)}
+			}
+			data = append(data, '\n')
+		} else {
+			var buf bytes.Buffer
+			for i := range subs {
+				buf.WriteString(subs[i].ID + "\n")
+			}
+			data = buf.Bytes()
+		}
+
+		if err := os.WriteFile(config.ExpandHome(path), data, 0o644); err != nil {
+			return actionDoneMsg{err: fmt.Errorf("failed to export: %w", err)}
+		}
+		return actionDoneMsg{message: fmt.Sprintf("Exported %s to %s", countSubscriptions(len(subs), ""), path)}
+	}
+}
+
+// removeAccounts removes the targets from the Azure CLI profile once the
+// prompt is answered with yes, then reloads the lists.
+func (m Model) removeAccounts(answer string) (tea.Model, tea.Cmd) {
+	subs := m.targets()
+	m.setView(ViewSubscriptions)
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		m.message = "Nothing removed"
+		return m, nil
+	}
+
+	ids := make([]string, len(subs))
+	marks := maps.Clone(m.marks)
+	for i := range subs {
+		ids[i] = subs[i].ID
+		delete(marks, subs[i].ID)
+	}
+	m.marks = marks
+
+	return m, func() tea.Msg {
+		if err := m.client.RemoveSubscriptions(context.Background(), ids); err != nil {
+			return actionDoneMsg{err: fmt.Errorf("failed to remove: %w", err)}
+		}
+		return actionDoneMsg{message: fmt.Sprintf("Removed %s from the Azure CLI profile", countSubscriptions(len(ids), "")), reload: true}
+	}
+}
+
+// addFavorites adds the targets to the favorites, saving them to the config
+// file when there is one.
+func (m Model) addFavorites(subs []azure.Subscription) (tea.Model, tea.Cmd) {
+	m.setView(ViewSubscriptions)
+
+	var added []string
+	for i := range subs {
+		if !m.isFavorite(&subs[i]) {
+			added = append(added, subs[i].ID)
+		}
+	}
+	if len(added) == 0 {
+		m.message = "Already favorites"
+		return m, nil
+	}
+	m.favorites = append(append([]string(nil), m.favorites...), added...)
+
+	if m.configPath == "" {
+		m.message = fmt.Sprintf("Added %s to favorites for this session", countSubscriptions(len(added), ""))
+		return m, nil
+	}
+
+	path := m.configPath
+	return m, func() tea.Msg {
+		if err := config.AddFavorites(path, added); err != nil {
+			return actionDoneMsg{err: fmt.Errorf("favorites not saved: %w", err)}
+		}
+		return actionDoneMsg{message: fmt.Sprintf("Added %s to favorites in %s", countSubscriptions(len(added), ""), path)}
+	}
+}
+
+// runCommand runs a configured command in each subscription, with its own
+// isolated Azure CLI environment, and reports every subscription's output.
+func runCommand(c config.Command, subs []azure.Subscription) tea.Cmd {
+	return func() tea.Msg {
+		var out bytes.Buffer
+		runner := &batch.Runner{Parallel: c.Parallel, Output: &out}
+		report := runner.Run(context.Background(), subs, batch.ShellCommand(c.Command))
+
+		msg := actionDoneMsg{
+			message: fmt.Sprintf("%s: %d succeeded, %d failed", c.Name, report.Succeeded, report.Failed),
+			output:  strings.TrimRight(out.String(), "\n"),
+		}
+		if report.Failed > 0 {
+			var failed []string
+			for i := range report.Results {
+				if r := &report.Results[i]; !r.Succeeded() {
+					failed = append(failed, r.SubscriptionName)
+				}
+			}
+			msg.err = fmt.Errorf("%s failed in %s", c.Name, strings.Join(failed, ", "))
+		}
+		return msg
+	}
+}
+
+// toggleMark marks or unmarks a subscription or tenant ID. The marks are
+// copied so that earlier models are unaffected.
+func (m *Model) toggleMark(id string) {
+	marks := maps.Clone(m.marks)
+	if marks[id] {
+		delete(marks, id)
+	} else {
+		marks[id] = true
+	}
+	m.marks = marks
+}
+
+// renderActions renders the heading, items and footer of the actions menu.
+func (m Model) renderActions() (heading string, items []string, footer string) {
+	heading = fmt.Sprintf("\n  %s\n\n", MutedStyle.Render("Apply to "+countSubscriptions(len(m.targets()), "")))
+	if m.inputMode != inputNone {
+		heading = fmt.Sprintf("\n  %s\n\n", m.input.View())
+	}
+
+	items = make([]string, 0, len(m.actions))
+	for i := range m.actions {
+		cursor, label := "  ", NormalStyle.Render(m.actions[i].label)
+		if i == m.actionCursor {
+			cursor, label = CursorStyle.Render("> "), SelectedStyle.Render(m.actions[i].label)
+		}
+		items = append(items, fmt.Sprintf("%s%s\n", cursor, label))
+	}
+
+	footer = fmt.Sprintf("\n  %s\n", MutedStyle.Render("esc to go back"))
+	return heading, items, footer
+}
+
+// countSubscriptions formats a number of subscriptions, or of their IDs.
+func countSubscriptions(n int, noun string) string {
+	if noun == "" {
+		noun = "subscription"
+	} else {
+		noun = "subscription " + noun
+	}
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// copyToClipboard copies text with the OSC 52 terminal sequence, which also
+// works over SSH.
+func copyToClipboard(text string) {
+	lipgloss.DefaultRenderer().Output().Copy(text)
+}
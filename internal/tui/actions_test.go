@@ -0,0 +1,313 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/l2D/azswitch/internal/azenv"
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+)
+
+func press(m Model, keys ...tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var next tea.Model
+		next, cmd = m.Update(k)
+		m = next.(Model)
+	}
+	return m, cmd
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var (
+	spaceMsg   = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	enterMsg   = tea.KeyMsg{Type: tea.KeyEnter}
+	downMsg    = tea.KeyMsg{Type: tea.KeyDown}
+	escapeMsg  = tea.KeyMsg{Type: tea.KeyEsc}
+	actionsMsg = runes("a")
+	upMsg      = tea.KeyMsg{Type: tea.KeyUp}
+)
+
+func TestModel_Marks_SurviveFilterAndRefresh(t *testing.T) {
+	m := readyModel(t, 4)
+
+	m, _ = press(m, spaceMsg, downMsg, spaceMsg) // marks Sub 01 and Sub 03
+	m, _ = press(m, runes("/"), runes("0"), runes("2"), enterMsg)
+	if len(m.subscriptions) != 1 || m.subscriptions[0].ID != "id-02" {
+		t.Fatalf("expected only Sub 02 to match, got %v", m.subscriptions)
+	}
+	if view := m.View(); !strings.Contains(view, "1 of 4") || !strings.Contains(view, "2 marked") {
+		t.Errorf("expected the filter line to count matches and marks, got:\n%s", view)
+	}
+
+	next, _ := m.Update(dataLoadedMsg{
+		account:       m.account,
+		subscriptions: m.allSubscriptions,
+		tenants:       m.tenants,
+	})
+	m = next.(Model)
+	if len(m.subscriptions) != 1 || len(m.markedSubscriptions()) != 2 {
+		t.Errorf("expected the filter and marks to survive a refresh, got %d shown, %d marked",
+			len(m.subscriptions), len(m.markedSubscriptions()))
+	}
+
+	m, _ = press(m, runes("/"), escapeMsg)
+	if m.filterText != "" || len(m.subscriptions) != 4 {
+		t.Errorf("expected esc to clear the filter, got %q with %d shown", m.filterText, len(m.subscriptions))
+	}
+}
+
+func TestModel_Actions_CopyIDs(t *testing.T) {
+	m := readyModel(t, 3)
+	var copied string
+	m.copyText = func(text string) { copied = text }
+
+	m, _ = press(m, spaceMsg, downMsg, spaceMsg, actionsMsg)
+	if m.view != ViewActions {
+		t.Fatalf("expected the actions menu, got view %v", m.view)
+	}
+	if !strings.Contains(m.View(), "Apply to 2 subscriptions") {
+		t.Errorf("expected the menu to name its targets, got:\n%s", m.View())
+	}
+
+	m, _ = press(m, enterMsg)
+	if copied != "id-01\nid-03" {
+		t.Errorf("expected the marked IDs to be copied, got %q", copied)
+	}
+	if m.view != ViewSubscriptions || !strings.Contains(m.message, "2 subscription IDs") {
+		t.Errorf("expected a confirmation in the list, got view %v and %q", m.view, m.message)
+	}
+}
+
+func TestModel_Actions_CursorWithoutMarks(t *testing.T) {
+	m := readyModel(t, 3)
+	var copied string
+	m.copyText = func(text string) { copied = text }
+
+	m, _ = press(m, downMsg, actionsMsg, enterMsg)
+	if copied != "id-02" {
+		t.Errorf("expected the subscription under the cursor to be copied, got %q", copied)
+	}
+}
+
+func TestModel_Actions_Export(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subs.json")
+	m := readyModel(t, 3)
+
+	m, _ = press(m, spaceMsg, spaceMsg, actionsMsg, downMsg, enterMsg)
+	if m.inputMode != inputExport || m.input.Value() != defaultExportFile {
+		t.Fatalf("expected the export prompt, got mode %v with %q", m.inputMode, m.input.Value())
+	}
+	m.input.SetValue(path)
+
+	m, cmd := press(m, enterMsg)
+	if cmd == nil {
+		t.Fatal("expected an export command")
+	}
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if m.warning != nil {
+		t.Fatalf("export failed: %v", m.warning)
+	}
+	if m.message != "Exported 2 subscriptions to "+path {
+		t.Errorf("expected a confirmation, got %q", m.message)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subs []azure.Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", data, err)
+	}
+	if len(subs) != 2 || subs[0].ID != "id-01" || subs[1].ID != "id-02" {
+		t.Errorf("expected the marked subscriptions, got %v", subs)
+	}
+}
+
+func TestModel_Actions_AddFavorites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("# mine\nfavorites:\n  - id-01\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := readyModel(t, 3, WithConfigFile(path))
+	m.favorites = []string{"id-01"}
+
+	m, _ = press(m, spaceMsg, spaceMsg, actionsMsg, downMsg, downMsg)
+	m, cmd := press(m, enterMsg)
+	if cmd == nil {
+		t.Fatal("expected the favorites to be saved")
+	}
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if m.warning != nil {
+		t.Fatalf("saving favorites failed: %v", m.warning)
+	}
+	if m.message != "Added 1 subscription to favorites in "+path {
+		t.Errorf("expected a confirmation, got %q", m.message)
+	}
+	if !m.isFavorite(&m.subscriptions[1]) {
+		t.Error("expected Sub 02 to be a favorite now")
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(cfg.Favorites, ",") != "id-01,id-02" {
+		t.Errorf("expected id-02 to be added once, got %v", cfg.Favorites)
+	}
+}
+
+func TestModel_Actions_RunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses POSIX shell")
+	}
+	// Each subscription runs in a copy of this Azure CLI config.
+	base := t.TempDir()
+	profile := `{"subscriptions":[{"id":"id-01","name":"Sub 01"},{"id":"id-02","name":"Sub 02"}]}`
+	if err := os.WriteFile(filepath.Join(base, azure.ProfileFile), []byte(profile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(azenv.EnvBaseConfigDir, base)
+
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "Greet", Command: "echo hello"},
+		{Name: "Fail", Command: "exit 3"},
+	}
+	m := readyModel(t, 3, WithConfig(cfg))
+
+	m, _ = press(m, spaceMsg, spaceMsg, actionsMsg, downMsg, downMsg, downMsg)
+	m, cmd := press(m, enterMsg)
+	next, _ := m.Update(commandDone(t, cmd))
+	m = next.(Model)
+	if m.warning != nil {
+		t.Fatalf("expected the command to succeed, got %v", m.warning)
+	}
+	if m.message != "Greet: 2 succeeded, 0 failed" || strings.Count(m.actionOutput, "hello") != 2 {
+		t.Errorf("expected a report and the output of both subscriptions, got %q and %q", m.message, m.actionOutput)
+	}
+
+	m, _ = press(m, actionsMsg, downMsg, downMsg, downMsg, downMsg)
+	m, cmd = press(m, enterMsg)
+	next, _ = m.Update(commandDone(t, cmd))
+	m = next.(Model)
+	if m.message != "Fail: 0 succeeded, 2 failed" {
+		t.Errorf("expected a report of the failures, got %q", m.message)
+	}
+	if m.warning == nil || !strings.Contains(m.warning.Error(), "Fail failed in Sub 01, Sub 02") {
+		t.Errorf("expected a warning naming the failed subscriptions, got %v", m.warning)
+	}
+}
+
+// commandDone runs the batch of a configured command and returns its result,
+// skipping the spinner.
+func commandDone(t *testing.T, cmd tea.Cmd) actionDoneMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected the command to run")
+	}
+	batch, _ := cmd().(tea.BatchMsg)
+	for _, c := range batch {
+		if done, ok := c().(actionDoneMsg); ok {
+			return done
+		}
+	}
+	t.Fatal("expected the command to report")
+	return actionDoneMsg{}
+}
+
+func TestModel_Actions_RemoveFromProfile(t *testing.T) {
+	m := readyModel(t, 3)
+	client := m.client.(*azure.MockClient)
+
+	m, _ = press(m, downMsg, spaceMsg, actionsMsg, downMsg, downMsg, downMsg, enterMsg)
+	if m.inputMode != inputRemove || !strings.Contains(m.View(), "Remove 1 subscription from the Azure CLI profile?") {
+		t.Fatalf("expected a confirmation prompt, got:\n%s", m.View())
+	}
+
+	m, cmd := press(m, runes("y"), enterMsg)
+	if cmd == nil {
+		t.Fatal("expected the subscription to be removed")
+	}
+	next, reload := m.Update(cmd())
+	m = next.(Model)
+	if strings.Join(client.Calls.RemoveSubscriptions, ",") != "id-02" || reload == nil {
+		t.Errorf("expected id-02 to be removed and the lists reloaded, got %v", client.Calls.RemoveSubscriptions)
+	}
+	if len(m.marks) != 0 || !strings.Contains(m.message, "Removed 1 subscription") {
+		t.Errorf("expected the mark to be dropped and a confirmation, got %v and %q", m.marks, m.message)
+	}
+}
+
+func TestModel_Actions_RemoveNeedsYes(t *testing.T) {
+	m := readyModel(t, 3)
+	client := m.client.(*azure.MockClient)
+
+	m, _ = press(m, actionsMsg, downMsg, downMsg, downMsg, enterMsg, enterMsg)
+	if len(client.Calls.RemoveSubscriptions) != 0 || m.message != "Nothing removed" {
+		t.Errorf("expected nothing to be removed without a yes, got %v (%q)", client.Calls.RemoveSubscriptions, m.message)
+	}
+
+	m = readyModel(t, 3, WithReadOnly())
+	m, _ = press(m, actionsMsg)
+	for _, a := range m.actions {
+		if a.kind == actionRemove {
+			t.Error("expected no remove action in read-only mode")
+		}
+	}
+}
+
+func TestModel_Actions_SwitchUser(t *testing.T) {
+	me, admin := azure.User{Name: "me@contoso.com"}, azure.User{Name: "admin@contoso.com"}
+	client := azure.NewMockClient()
+	next, _ := NewModel(client).Update(dataLoadedMsg{
+		account: &azure.Account{Name: "Dev", ID: "dev", User: me},
+		subscriptions: []azure.Subscription{
+			{Name: "Dev", ID: "dev", TenantID: "t1", User: me, IsDefault: true},
+			{Name: "Prod", ID: "prod", TenantID: "t1", User: admin},
+		},
+	})
+	m := next.(Model)
+
+	if view := m.View(); !strings.Contains(view, "dev · me@contoso.com") || !strings.Contains(view, "prod · admin@contoso.com") {
+		t.Fatalf("expected the user of each subscription, got:\n%s", view)
+	}
+
+	m, _ = press(m, actionsMsg)
+	last := m.actions[len(m.actions)-1]
+	if last.kind != actionSwitchUser || last.user.Name != admin.Name {
+		t.Fatalf("expected a switch to the other user, got %+v", m.actions)
+	}
+
+	done, ok := m.switchUser(m.subscriptions[0], admin)().(switchedMsg)
+	if !ok || done.message != "Switched to Dev as admin@contoso.com" {
+		t.Errorf("expected a switch as admin, got %#v", done)
+	}
+	if strings.Join(client.Calls.SetSubscriptionUser, ",") != "dev=admin@contoso.com" {
+		t.Errorf("expected dev to use admin, got %v", client.Calls.SetSubscriptionUser)
+	}
+
+	// Without a token of that user, the tenant is logged in to first.
+	client.GetAccessTokenFunc = func(_ context.Context, _ string) (*azure.AccessToken, error) {
+		return nil, fmt.Errorf("%w: %w: AADSTS50076", azure.ErrCommandFailed, azure.ErrAuthRequired)
+	}
+	login, ok := m.switchUser(m.subscriptions[0], admin)().(preHooksDoneMsg)
+	if !ok || login.event.NewTenantID != "t1" || login.event.NewSubscriptionID != "dev" {
+		t.Errorf("expected a login to t1 before switching to dev, got %#v", login)
+	}
+}
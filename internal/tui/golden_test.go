@@ -0,0 +1,246 @@
+package tui
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenNow is the clock of every session, so that token lifetimes render
+// the same whenever the tests run.
+var goldenNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// ansiSequence matches the escape sequences lipgloss emits.
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// session drives a Model the way a tea.Program does, without a terminal:
+// commands run synchronously and their messages are fed back in.
+type session struct {
+	t *testing.T
+	m Model
+}
+
+// newSession starts a model in a window of the given size and runs its
+// Init commands, which load the client's data.
+func newSession(t *testing.T, client azure.Client, width, height int, opts ...Option) *session {
+	t.Helper()
+	s := &session{t: t, m: NewModel(client, opts...)}
+	s.m.now = func() time.Time { return goldenNow }
+	// A blinking cursor would schedule timers; a static one renders the same.
+	s.m.input.Cursor.SetMode(cursor.CursorStatic)
+	s.send(tea.WindowSizeMsg{Width: width, Height: height})
+	s.run(s.m.Init())
+	return s
+}
+
+// send updates the model with each message, running the resulting commands.
+func (s *session) send(msgs ...tea.Msg) {
+	s.t.Helper()
+	for _, msg := range msgs {
+		next, cmd := s.m.Update(msg)
+		s.m = next.(Model)
+		s.run(cmd)
+	}
+}
+
+// keys sends key presses.
+func (s *session) keys(keys ...tea.KeyMsg) {
+	s.t.Helper()
+	for _, k := range keys {
+		s.send(k)
+	}
+}
+
+// typeText types text one rune at a time.
+func (s *session) typeText(text string) {
+	s.t.Helper()
+	for _, r := range text {
+		s.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// run runs a command and feeds its message back. Spinner ticks are
+// dropped, since the spinner would tick forever.
+func (s *session) run(cmd tea.Cmd) {
+	s.t.Helper()
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case nil, spinner.TickMsg, tea.QuitMsg:
+	case tea.BatchMsg:
+		for _, c := range msg {
+			s.run(c)
+		}
+	default:
+		s.send(msg)
+	}
+}
+
+// snapshot compares the view with the golden files of the test: name.golden
+// without ANSI sequences and name.ansi.golden as rendered. With -update the
+// files are rewritten instead.
+func (s *session) snapshot() {
+	s.t.Helper()
+	raw := s.m.View()
+	golden(s.t, s.t.Name()+".golden", ansiSequence.ReplaceAllString(raw, ""))
+	golden(s.t, s.t.Name()+".ansi.golden", raw)
+}
+
+// golden compares got with the golden file at testdata/golden/name.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", filepath.FromSlash(name))
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("view does not match %s (run go test -update if intended)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// goldenClient returns a mock client with two tenants of three
+// subscriptions, signed in to Contoso Production. Fabrikam's token has
+// expired; Contoso's is valid for two hours.
+func goldenClient() *azure.MockClient {
+	client := azure.NewMockClient()
+	client.GetCurrentAccountFunc = func(_ context.Context) (*azure.Account, error) {
+		return &azure.Account{
+			Name: "Contoso Production", ID: "aaaaaaaa-0000-0000-0000-000000000001",
+			TenantID: "tenant-contoso", TenantDisplayName: "Contoso",
+			User: azure.User{Name: "alice@contoso.com"},
+		}, nil
+	}
+	client.ListSubscriptionsFunc = func(_ context.Context) ([]azure.Subscription, error) {
+		return []azure.Subscription{
+			{Name: "Contoso Production", ID: "aaaaaaaa-0000-0000-0000-000000000001", TenantID: "tenant-contoso", TenantDisplayName: "Contoso", State: "Enabled", IsDefault: true},
+			{Name: "Contoso Staging", ID: "aaaaaaaa-0000-0000-0000-000000000002", TenantID: "tenant-contoso", TenantDisplayName: "Contoso", State: "Enabled"},
+			{Name: "Fabrikam Dev", ID: "bbbbbbbb-0000-0000-0000-000000000001", TenantID: "tenant-fabrikam", TenantDisplayName: "Fabrikam", State: "Disabled"},
+		}, nil
+	}
+	client.ListTenantsFunc = func(_ context.Context) ([]azure.Tenant, error) {
+		return []azure.Tenant{
+			{DisplayName: "Contoso", TenantID: "tenant-contoso", DefaultDomain: "contoso.onmicrosoft.com"},
+			{DisplayName: "Fabrikam", TenantID: "tenant-fabrikam", DefaultDomain: "fabrikam.onmicrosoft.com"},
+		}, nil
+	}
+	client.GetAccessTokenFunc = func(_ context.Context, tenantID string) (*azure.AccessToken, error) {
+		if tenantID == "tenant-fabrikam" {
+			return nil, fmt.Errorf("%w: %w: AADSTS700082", azure.ErrCommandFailed, azure.ErrAuthRequired)
+		}
+		return &azure.AccessToken{Tenant: tenantID, ExpiresOn: goldenNow.Add(2 * time.Hour).Unix()}, nil
+	}
+	return client
+}
+
+// goldenConfig adds a favorite and an alias to the default configuration.
+func goldenConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Favorites = []string{"Contoso Production"}
+	cfg.Aliases = map[string]string{"stg": "Contoso Staging"}
+	return cfg
+}
+
+func TestGolden(t *testing.T) {
+	restoreStyles(t)
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	if err := ApplyTheme("dark"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		width, height int
+		opts          []Option
+		script        func(s *session)
+	}{
+		{
+			name: "subscriptions", width: 80, height: 24,
+			script: func(s *session) {
+				s.keys(downMsg)
+			},
+		},
+		{
+			name: "directories", width: 80, height: 24,
+			script: func(s *session) {
+				s.keys(tea.KeyMsg{Type: tea.KeyTab}, downMsg, downMsg, downMsg)
+			},
+		},
+		{
+			name: "directories_collapsed_narrow", width: 50, height: 24,
+			script: func(s *session) {
+				s.keys(tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyLeft})
+			},
+		},
+		{
+			name: "filter", width: 80, height: 24,
+			script: func(s *session) {
+				s.keys(runes("/"))
+				s.typeText("tenant=contoso")
+				s.keys(enterMsg)
+			},
+		},
+		{
+			name: "actions_on_marked", width: 80, height: 24,
+			script: func(s *session) {
+				s.keys(spaceMsg, spaceMsg, actionsMsg)
+			},
+		},
+		{
+			name: "help", width: 100, height: 30,
+			script: func(s *session) {
+				s.keys(runes("?"))
+			},
+		},
+		{
+			// Too short for the boxed header, which shrinks to one line.
+			name: "short_window", width: 80, height: 10,
+			script: func(s *session) {
+				s.keys(downMsg, downMsg)
+			},
+		},
+		{
+			name: "inline", width: 80, height: 24, opts: []Option{WithInline(8)},
+			script: func(s *session) {
+				s.keys(downMsg)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithConfig(goldenConfig())}, tt.opts...)
+			s := newSession(t, goldenClient(), tt.width, tt.height, opts...)
+			tt.script(s)
+			s.snapshot()
+		})
+	}
+}
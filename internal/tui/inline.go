@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// minInlineHeight fits the header, tabs, one item and the help line.
+const minInlineHeight = 4
+
+// inlineFrame renders the compact screen of inline mode: one line per item
+// and at most m.inline lines in total.
+func (m Model) inlineFrame() frame {
+	f := frame{tabsLine: -1}
+	var top, bottom strings.Builder
+
+	top.WriteString(m.renderInlineHeader())
+	top.WriteString("\n")
+
+	switch m.state {
+	case StateLoading:
+		top.WriteString(fmt.Sprintf("  %s Loading...\n", m.spinner.View()))
+	case StateError:
+		top.WriteString(fmt.Sprintf("  %s %s\n", ErrorStyle.Render("Error:"), m.err.Error()))
+	case StateSwitching:
+		top.WriteString(fmt.Sprintf("  %s Switching...\n", m.spinner.View()))
+	default:
+		f.tabsLine = strings.Count(top.String(), "\n")
+		tabs, spans := m.renderTabs()
+		f.tabs = spans
+		top.WriteString(tabs)
+		top.WriteString("\n")
+
+		if line := m.renderFilterLine(); line != "" && m.view == ViewSubscriptions {
+			top.WriteString(strings.TrimSuffix(line, "\n"))
+		}
+		if m.view == ViewActions && m.inputMode != inputNone {
+			top.WriteString("  " + m.input.View() + "\n")
+		}
+
+		f.items = m.renderInlineItems()
+		if len(f.items) == 0 {
+			top.WriteString(MutedStyle.Render("  Nothing to show"))
+			top.WriteString("\n")
+		}
+		bottom.WriteString(m.renderInlineStatus())
+	}
+
+	bottom.WriteString(m.help.View(m.keys))
+
+	f.top, f.bottom = top.String(), bottom.String()
+	height := m.inline
+	if m.height > 0 {
+		height = min(height, m.height)
+	}
+	f.height = max(height-strings.Count(f.top, "\n")-strings.Count(f.bottom, "\n")-1, 1)
+	return f
+}
+
+// renderInlineHeader renders the title and current subscription on one line.
+func (m Model) renderInlineHeader() string {
+	title := TitleStyle.UnsetMarginBottom().Render("azswitch")
+	if m.readOnly {
+		title += " " + ReadOnlyBadgeStyle.Render("READ-ONLY")
+	}
+	if m.account == nil {
+		return title
+	}
+
+	name := CurrentStyle
+	if accent, ok := AccentColor(m.accents, m.account.ID, m.account.Name); ok {
+		name = name.Foreground(accent)
+	}
+	return fmt.Sprintf("%s %s %s %s", title, MutedStyle.Render("·"), name.Render(m.account.Name), MutedStyle.Render("("+m.account.TenantDisplayName+")"))
+}
+
+// renderInlineItems renders one line per item of the current view.
+func (m Model) renderInlineItems() []string {
+	var items []string
+	switch m.view {
+	case ViewSubscriptions:
+		showUsers := len(azure.Users(m.allSubscriptions)) > 1
+		for i, row := range m.rows() {
+			if row.group != nil {
+				items = append(items, fmt.Sprintf("%s%s\n", inlineCursor(i == m.cursor), renderGroupHeader(row, i == m.cursor)))
+				continue
+			}
+			sub := row.sub
+			name := inlineName(sub.Name, sub.IsDefault, i == m.cursor)
+			if m.isFavorite(sub) {
+				name = FavoriteStyle.Render("★ ") + name
+			}
+			if alias := m.aliasOf(sub); alias != "" {
+				name += " " + MutedStyle.Render("("+alias+")")
+			}
+			if badges := m.tagBadges(sub); badges != "" {
+				name += "  " + badges
+			}
+			detail := sub.ID
+			if showUsers && sub.User.Name != "" {
+				detail += " · " + sub.User.Name
+			}
+			items = append(items, fmt.Sprintf("%s%s%s  %s\n", inlineCursor(i == m.cursor), m.markColumn(sub.ID), name, MutedStyle.Render(detail)))
+		}
+
+	case ViewDirectories:
+		for i, row := range m.dirRows() {
+			selected := i == m.tenantCursor
+			if row.sub != nil {
+				name := inlineName(row.sub.Name, row.sub.IsDefault, selected)
+				items = append(items, fmt.Sprintf("%s    %s %s\n", inlineCursor(selected), MutedStyle.Render("•"), name))
+				continue
+			}
+			isCurrent := m.account != nil && row.tenant.TenantID == m.account.TenantID
+			name := inlineName(row.tenant.Title(), isCurrent, selected)
+			count := MutedStyle.Render(fmt.Sprintf("%d subscriptions", row.count))
+			if badge := m.tokenBadge(row.tenant.TenantID, m.now()); badge != "" {
+				count += "  " + badge
+			}
+			items = append(items, fmt.Sprintf("%s%s%s  %s\n", inlineCursor(selected), m.markColumn(row.tenant.TenantID), name, count))
+		}
+
+	case ViewClusters:
+		for i := range m.clusters {
+			cluster := &m.clusters[i]
+			isCurrent := m.kubeContext != "" && strings.EqualFold(m.kubeContext, m.contextFor(cluster.Name))
+			name := inlineName(cluster.Name, isCurrent, i == m.clusterCursor)
+			where := MutedStyle.Render(cluster.ResourceGroup + " · " + cluster.Location)
+			items = append(items, fmt.Sprintf("%s%s  %s\n", inlineCursor(i == m.clusterCursor), name, where))
+		}
+
+	case ViewActions:
+		for i := range m.actions {
+			name := inlineName(m.actions[i].label, false, i == m.actionCursor)
+			items = append(items, fmt.Sprintf("%s%s\n", inlineCursor(i == m.actionCursor), name))
+		}
+	}
+	return items
+}
+
+// inlineCursor renders the cursor column of an item.
+func inlineCursor(selected bool) string {
+	if selected {
+		return CursorStyle.Render("> ")
+	}
+	return "  "
+}
+
+// inlineName styles an item name like the full-screen lists do.
+func inlineName(name string, current, selected bool) string {
+	switch {
+	case current:
+		return CurrentStyle.Render(name + " ✓")
+	case selected:
+		return SelectedStyle.Render(name)
+	default:
+		return NormalStyle.Render(name)
+	}
+}
+
+// renderInlineStatus renders the result of the last switch on one line.
+func (m Model) renderInlineStatus() string {
+	switch {
+	case m.hookErr != nil:
+		return fmt.Sprintf("  %s %s\n", WarningStyle.Render("Hook failed:"), firstLine(m.hookErr.Error()))
+	case m.warning != nil:
+		return fmt.Sprintf("  %s %s\n", WarningStyle.Render("Warning:"), firstLine(m.warning.Error()))
+	case m.message != "":
+		return fmt.Sprintf("  %s\n", SuccessStyle.Render(m.message))
+	}
+	return ""
+}
+
+// renderSummary renders the line left in the terminal when inline mode exits.
+func (m Model) renderSummary() string {
+	if m.summary != "" {
+		return SuccessStyle.Render("✓") + " " + m.summary
+	}
+	if m.account != nil {
+		return MutedStyle.Render("·") + " " + fmt.Sprintf("Kept %s (%s)", m.account.Name, m.account.ID)
+	}
+	return MutedStyle.Render("·") + " Nothing switched"
+}
+
+// switchSummary describes a completed switch for the exit summary.
+func (m Model) switchSummary(msg switchedMsg) string {
+	for i := range m.subscriptions {
+		if m.subscriptions[i].ID == msg.subscriptionID {
+			return fmt.Sprintf("Switched to %s (%s)", m.subscriptions[i].Name, msg.subscriptionID)
+		}
+	}
+	return msg.message
+}
+
+// joinSummary appends a part to the exit summary.
+func joinSummary(summary, part string) string {
+	if summary == "" {
+		return part
+	}
+	return summary + ", " + part
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/filter"
+	"github.com/l2D/azswitch/internal/listing"
+)
+
+// inputMode is what the text input is collecting.
+type inputMode int
+
+const (
+	inputNone inputMode = iota
+	inputFilter
+	inputExport
+	inputRemove
+)
+
+// startInput focuses the text input for a prompt.
+func (m *Model) startInput(mode inputMode, prompt, value string) tea.Cmd {
+	m.inputMode = mode
+	m.input.Prompt = prompt
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// stopInput hides the text input.
+func (m *Model) stopInput() {
+	m.inputMode = inputNone
+	m.input.Blur()
+}
+
+// handleInputKey handles a key while a prompt is open. The filter applies as
+// it is typed; esc clears it.
+func (m Model) handleInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		mode, value := m.inputMode, m.input.Value()
+		m.stopInput()
+		switch mode {
+		case inputExport:
+			return m.export(value)
+		case inputRemove:
+			return m.removeAccounts(value)
+		}
+		return m, nil
+
+	case tea.KeyEsc:
+		if m.inputMode == inputFilter {
+			m.filterText = ""
+			m.applyFilter()
+		}
+		m.stopInput()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.inputMode == inputFilter {
+		m.filterText = m.input.Value()
+		m.applyFilter()
+	}
+	return m, cmd
+}
+
+// applyFilter shows the subscriptions matching the filter, keeping the cursor
+// on the same subscription when it still matches. An invalid filter keeps
+// the previous list.
+func (m *Model) applyFilter() {
+	f, err := filter.Parse(m.filterText)
+	m.filterErr = err
+	if err != nil {
+		return
+	}
+
+	key := m.selectedKey()
+	m.subscriptions = listing.Sort(f.Apply(m.allSubscriptions), m.sortOrder, m.usage)
+	m.moveCursorTo(key)
+}
+
+// renderFilterLine renders the filter prompt, or the active filter, the
+// order when it is not the default and the number of marked subscriptions,
+// above the subscriptions list.
+func (m Model) renderFilterLine() string {
+	var line string
+	switch {
+	case m.inputMode == inputFilter:
+		line = "  " + m.input.View()
+	case m.filterText != "":
+		line = fmt.Sprintf("  %s %s", MutedStyle.Render("Filter:"), m.filterText)
+	}
+
+	if m.filterText != "" {
+		line += "  " + MutedStyle.Render(fmt.Sprintf("%d of %d", len(m.subscriptions), len(m.allSubscriptions)))
+	}
+	if m.filterErr != nil {
+		line += "  " + WarningStyle.Render(m.filterErr.Error())
+	}
+	if m.sortOrder != config.SortName || m.grouping != config.GroupNone {
+		if line == "" {
+			line = " "
+		}
+		order := "sorted by " + sortLabels[m.sortOrder]
+		if m.grouping != config.GroupNone {
+			order += ", grouped by " + m.grouping
+		}
+		line += "  " + MutedStyle.Render(order)
+	}
+	if marked := len(m.markedSubscriptions()); marked > 0 {
+		if line == "" {
+			line = " "
+		}
+		line += "  " + SelectedStyle.Render(fmt.Sprintf("%d marked", marked))
+	}
+
+	if line == "" {
+		return ""
+	}
+	return line + "\n\n"
+}
@@ -1,55 +1,58 @@
 package tui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/l2D/azswitch/internal/config"
+)
 
 // KeyMap defines the key bindings for the application.
 type KeyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Select  key.Binding
-	Tab     key.Binding
-	Help    key.Binding
-	Quit    key.Binding
-	Refresh key.Binding
-	Back    key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Select   key.Binding
+	Tab      key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+	Refresh  key.Binding
+	Back     key.Binding
+	Clusters key.Binding
+	Mark     key.Binding
+	Filter   key.Binding
+	Actions  key.Binding
+	Sort     key.Binding
+	Group    key.Binding
+	Expand   key.Binding
+	Collapse key.Binding
 }
 
-// DefaultKeyMap returns the default key bindings.
+// DefaultKeyMap returns the default key bindings. The keys come from
+// config.DefaultKeys, so the config file and the TUI agree on them.
 func DefaultKeyMap() KeyMap {
-	return KeyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "up"),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "down"),
-		),
-		Select: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
-		),
-		Tab: key.NewBinding(
-			key.WithKeys("tab"),
-			key.WithHelp("tab", "switch view"),
-		),
-		Help: key.NewBinding(
-			key.WithKeys("?"),
-			key.WithHelp("?", "help"),
-		),
-		Quit: key.NewBinding(
-			key.WithKeys("q", "ctrl+c"),
-			key.WithHelp("q", "quit"),
-		),
-		Refresh: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
-		),
-		Back: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "back"),
-		),
+	k := KeyMap{
+		Up:       key.NewBinding(key.WithHelp("", "up")),
+		Down:     key.NewBinding(key.WithHelp("", "down")),
+		Select:   key.NewBinding(key.WithHelp("", "select")),
+		Tab:      key.NewBinding(key.WithHelp("", "switch view")),
+		Help:     key.NewBinding(key.WithHelp("", "help")),
+		Quit:     key.NewBinding(key.WithHelp("", "quit")),
+		Refresh:  key.NewBinding(key.WithHelp("", "refresh")),
+		Back:     key.NewBinding(key.WithHelp("", "back")),
+		Clusters: key.NewBinding(key.WithHelp("", "aks clusters")),
+		Mark:     key.NewBinding(key.WithHelp("", "mark")),
+		Filter:   key.NewBinding(key.WithHelp("", "filter")),
+		Actions:  key.NewBinding(key.WithHelp("", "actions")),
+		Sort:     key.NewBinding(key.WithHelp("", "sort")),
+		Group:    key.NewBinding(key.WithHelp("", "group")),
+		Expand:   key.NewBinding(key.WithHelp("", "expand")),
+		Collapse: key.NewBinding(key.WithHelp("", "collapse")),
 	}
+	for _, action := range config.KeyActions {
+		k.Override(action, config.DefaultKeys[action])
+	}
+	return k
 }
 
 // ShortHelp returns a short help text.
@@ -60,8 +63,125 @@ func (k KeyMap) ShortHelp() []key.Binding {
 // FullHelp returns the full help text.
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Select},
-		{k.Tab, k.Refresh, k.Back},
+		{k.Up, k.Down, k.Select, k.Mark},
+		{k.Tab, k.Filter, k.Actions, k.Refresh},
+		{k.Sort, k.Group, k.Expand, k.Collapse},
+		{k.Back, k.Clusters},
 		{k.Help, k.Quit},
 	}
 }
+
+// binding returns the binding for a configurable action, as named in config.KeyActions.
+func (k *KeyMap) binding(action string) *key.Binding {
+	switch action {
+	case "up":
+		return &k.Up
+	case "down":
+		return &k.Down
+	case "select":
+		return &k.Select
+	case "tab":
+		return &k.Tab
+	case "help":
+		return &k.Help
+	case "quit":
+		return &k.Quit
+	case "refresh":
+		return &k.Refresh
+	case "back":
+		return &k.Back
+	case "clusters":
+		return &k.Clusters
+	case "mark":
+		return &k.Mark
+	case "filter":
+		return &k.Filter
+	case "actions":
+		return &k.Actions
+	case "sort":
+		return &k.Sort
+	case "group":
+		return &k.Group
+	case "expand":
+		return &k.Expand
+	case "collapse":
+		return &k.Collapse
+	}
+	return nil
+}
+
+// KeyMapFromConfig returns the default key bindings with the config's overrides applied.
+func KeyMapFromConfig(cfg *config.Config) KeyMap {
+	k := DefaultKeyMap()
+	for action, keys := range cfg.Keys {
+		k.Override(action, keys)
+	}
+	return k
+}
+
+// Lookup returns the binding of an action, as named in config.KeyActions.
+func (k KeyMap) Lookup(action string) (key.Binding, bool) {
+	b := k.binding(action)
+	if b == nil {
+		return key.Binding{}, false
+	}
+	return *b, true
+}
+
+// Override replaces the keys of an action. The help text lists the new keys,
+// so ShortHelp and FullHelp reflect the change.
+func (k *KeyMap) Override(action string, keys []string) {
+	b := k.binding(action)
+	if b == nil || len(keys) == 0 {
+		return
+	}
+	bound := make([]string, len(keys))
+	for i, name := range keys {
+		bound[i] = name
+		if name == spaceKey {
+			bound[i] = " "
+		}
+	}
+	b.SetKeys(bound...)
+	b.SetHelp(helpKeys(keys), b.Help().Desc)
+}
+
+// spaceKey is how the space bar is written in the config file. Bubble Tea
+// reports it as " ".
+const spaceKey = "space"
+
+// KeyNames returns the keys of a binding as written in the config file.
+func KeyNames(b key.Binding) []string {
+	names := make([]string, len(b.Keys()))
+	for i, k := range b.Keys() {
+		names[i] = k
+		if k == " " {
+			names[i] = spaceKey
+		}
+	}
+	return names
+}
+
+// keyLabels shortens key names for the help view.
+var keyLabels = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+// helpKeys formats keys for the help view. The reserved quit key is left
+// out, since it always works.
+func helpKeys(keys []string) string {
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == config.ReservedKey && len(keys) > 1 {
+			continue
+		}
+		if label, ok := keyLabels[k]; ok {
+			k = label
+		}
+		labels = append(labels, k)
+	}
+	return strings.Join(labels, "/")
+}
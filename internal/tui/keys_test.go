@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/l2D/azswitch/internal/config"
+)
+
+func TestDefaultKeyMap_MatchesConfigDefaults(t *testing.T) {
+	keys := DefaultKeyMap()
+
+	for _, action := range config.KeyActions {
+		b, ok := keys.Lookup(action)
+		if !ok {
+			t.Errorf("action %q has no binding", action)
+			continue
+		}
+		if !slices.Equal(KeyNames(b), config.DefaultKeys[action]) {
+			t.Errorf("action %q: keymap has %v, config.DefaultKeys has %v", action, KeyNames(b), config.DefaultKeys[action])
+		}
+	}
+}
+
+func TestDefaultKeyMap_Help(t *testing.T) {
+	keys := DefaultKeyMap()
+
+	for _, tt := range []struct {
+		binding   key.Binding
+		key, desc string
+	}{
+		{keys.Up, "↑/k", "up"},
+		{keys.Quit, "q", "quit"},
+		{keys.Mark, "space", "mark"},
+		{keys.Collapse, "←/h", "collapse"},
+	} {
+		if help := tt.binding.Help(); help.Key != tt.key || help.Desc != tt.desc {
+			t.Errorf("expected help %q %q, got %q %q", tt.key, tt.desc, help.Key, help.Desc)
+		}
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, keys.Mark) {
+		t.Error("expected space to mark")
+	}
+}
+
+func TestKeyMapFromConfig_UpdatesHelp(t *testing.T) {
+	cfg := config.Default()
+	cfg.Keys = map[string][]string{
+		"down": {"down", "ctrl+n"},
+		"quit": {"ctrl+q", "ctrl+c"},
+	}
+
+	keys := KeyMapFromConfig(cfg)
+
+	if got := keys.Down.Help().Key; got != "↓/ctrl+n" {
+		t.Errorf("expected down help '↓/ctrl+n', got '%s'", got)
+	}
+
+	if got := keys.Quit.Help().Key; got != "ctrl+q" {
+		t.Errorf("expected quit help 'ctrl+q', got '%s'", got)
+	}
+
+	var short []string
+	for _, b := range keys.ShortHelp() {
+		short = append(short, b.Help().Key)
+	}
+	if !strings.Contains(strings.Join(short, " "), "ctrl+q") {
+		t.Errorf("expected short help to show the new quit key, got %v", short)
+	}
+}
@@ -2,16 +2,28 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/history"
+	"github.com/l2D/azswitch/internal/hooks"
+	"github.com/l2D/azswitch/internal/kubeconfig"
+	"github.com/l2D/azswitch/internal/listing"
+	"github.com/l2D/azswitch/internal/tags"
+	"github.com/l2D/azswitch/internal/tokens"
 )
 
 // ViewType represents the current view.
@@ -20,6 +32,8 @@ type ViewType int
 const (
 	ViewSubscriptions ViewType = iota
 	ViewDirectories
+	ViewClusters
+	ViewActions
 )
 
 // State represents the application state.
@@ -38,22 +52,108 @@ type Model struct {
 	// Azure client
 	client azure.Client
 
+	// Switch hooks
+	hooks *hooks.Runner
+
+	// AKS kubeconfig integration
+	kube config.Kubernetes
+
+	// Read-only mode: nothing can be switched
+	readOnly bool
+
+	// Display settings from the config file
+	aliases         map[string]string
+	favorites       []string
+	accents         map[string]string
+	quitAfterSwitch bool
+
 	// Current state
 	state State
 
 	// Current view
 	view ViewType
 
-	// Data
-	account       *azure.Account
-	subscriptions []azure.Subscription
-	tenants       []azure.Tenant
+	// First list item shown when the list is taller than the terminal
+	offset int
+
+	// Inline mode: at most this many lines, without the alternate screen
+	inline int
+
+	// What was switched, shown on exit in inline mode
+	summary string
+
+	// Picker mode: selecting records the choice and quits instead of switching
+	picker        bool
+	pickedSubs    []azure.Subscription
+	pickedTenants []azure.Tenant
+
+	// Marked subscription and tenant IDs, when marking is enabled
+	marking bool
+	marks   map[string]bool
+
+	// Filter expression applied to the subscriptions list
+	filterText string
+	filterErr  error
+
+	// Order of the subscriptions list, its collapsed groups, and the recorded
+	// switches that the recent and usage orders sort by
+	sortOrder   string
+	grouping    string
+	collapsed   map[string]bool
+	usage       *history.History
+	historyPath string
+
+	// Text input for the filter and export prompts
+	input     textinput.Model
+	inputMode inputMode
+
+	// Actions menu on the marked subscriptions
+	actions      []action
+	actionCursor int
+	commands     []config.Command
+	configPath   string
+	copyText     func(string)
+	activity     string
+	actionOutput string
+
+	// Last click, to detect double-clicks
+	lastClick     time.Time
+	lastClickItem int
+	lastClickView ViewType
+
+	// Data; subscriptions holds those matching the filter, in sort order
+	account          *azure.Account
+	allSubscriptions []azure.Subscription
+	subscriptions    []azure.Subscription
+	tenants          []azure.Tenant
+
+	// Token status per tenant ID, checked after a load when unknown, close
+	// to expiry or the tenant was switched to
+	tokenStatus map[string]tokens.Status
+
+	// now is the clock, replaced in tests.
+	now func() time.Time
+
+	// Subscription tags: the cache file, how long cached tags are used and
+	// the tag names shown as badges. Tags are fetched only with a cache file.
+	tagsPath string
+	tagsTTL  time.Duration
+	tagNames []string
+
+	// AKS clusters of the subscription last switched to
+	clusters            []azure.AKSCluster
+	clusterSubscription string
+	kubeContext         string
 
 	// UI state
-	cursor       int
-	tenantCursor int
-	err          error
-	message      string
+	cursor        int
+	tenantCursor  int
+	clusterCursor int
+	err           error
+	message       string
+	warning       error
+	hookOutput    string
+	hookErr       error
 
 	// Components
 	spinner spinner.Model
@@ -81,16 +181,160 @@ type (
 		account       *azure.Account
 		subscriptions []azure.Subscription
 		tenants       []azure.Tenant
+		usage         *history.History
+	}
+
+	// tokensCheckedMsg is sent when the tokens of the tenants are checked.
+	tokensCheckedMsg struct {
+		statuses []tokens.Status
+	}
+
+	// tagsFetchedMsg is sent when the stale tags of the subscriptions are
+	// fetched. tags holds the tags of every subscription, by ID.
+	tagsFetchedMsg struct {
+		tags map[string]map[string]string
+		err  error
 	}
 
 	// switchedMsg is sent when a switch operation completes.
 	switchedMsg struct {
+		message        string
+		subscriptionID string
+		tenantID       string
+		hookOutput     string
+		hookErr        error
+	}
+
+	// clustersLoadedMsg is sent when the AKS clusters of a subscription are loaded.
+	clustersLoadedMsg struct {
+		subscriptionID string
+		clusters       []azure.AKSCluster
+		kubeContext    string
+		err            error
+	}
+
+	// kubeContextSetMsg is sent when the kubectl context has been switched.
+	kubeContextSetMsg struct {
+		context string
+	}
+
+	// preHooksDoneMsg is sent when the pre-switch hooks for a tenant login have
+	// passed. The event names a subscription to switch to after the login, if any.
+	preHooksDoneMsg struct {
+		event  hooks.Event
+		output string
+		login  azure.LoginOptions
+	}
+
+	// tenantLoggedInMsg is sent when the interactive tenant login completes.
+	tenantLoggedInMsg struct {
+		event  hooks.Event
+		output string
+	}
+
+	// actionDoneMsg is sent when an action on the marked subscriptions completes.
+	actionDoneMsg struct {
 		message string
+		output  string
+		err     error
+
+		// reload is set when the action changed the subscriptions.
+		reload bool
 	}
 )
 
+// Option configures a Model.
+type Option func(*Model)
+
+// WithHooks runs the given hooks around subscription and tenant switches.
+func WithHooks(r *hooks.Runner) Option {
+	return func(m *Model) {
+		m.hooks = r
+	}
+}
+
+// WithKubernetes enables AKS kubeconfig context sync after subscription switches.
+func WithKubernetes(cfg config.Kubernetes) Option {
+	return func(m *Model) {
+		m.kube = cfg
+	}
+}
+
+// WithConfig applies the display and behavior settings of the config file:
+// default view, key bindings, aliases, favorites and behavior toggles. Hooks,
+// the Kubernetes integration and read-only mode have their own options.
+func WithConfig(cfg *config.Config) Option {
+	return func(m *Model) {
+		if cfg.DefaultView == config.ViewDirectories {
+			m.view = ViewDirectories
+		}
+		m.keys = KeyMapFromConfig(cfg)
+		m.aliases = cfg.Aliases
+		m.favorites = cfg.Favorites
+		m.accents = cfg.Accents
+		m.commands = cfg.Commands
+		m.sortOrder = cfg.List.Sort
+		m.grouping = cfg.List.Group
+		m.quitAfterSwitch = cfg.Behavior.QuitAfterSwitch
+		m.tagsTTL = cfg.Tags.TTL
+		m.tagNames = cfg.Tags.Show
+	}
+}
+
+// WithInline renders a compact picker of at most height lines below the
+// prompt instead of a full screen, and leaves a one-line summary on exit.
+func WithInline(height int) Option {
+	return func(m *Model) {
+		m.inline = max(height, minInlineHeight)
+	}
+}
+
+// WithPicker makes selecting an item record it and quit, without switching
+// anything. With multi, items can be marked and all marked items are picked.
+// Read the choice with Picked.
+func WithPicker(multi bool) Option {
+	return func(m *Model) {
+		m.picker = true
+		m.marking = multi
+		m.client = azure.NewReadOnlyClient(m.client)
+	}
+}
+
+// WithConfigFile sets the config file that actions such as adding favorites
+// write to.
+func WithConfigFile(path string) Option {
+	return func(m *Model) {
+		m.configPath = path
+	}
+}
+
+// WithHistory records subscription switches in the history file at path,
+// which the recent and usage sort orders read.
+func WithHistory(path string) Option {
+	return func(m *Model) {
+		m.historyPath = path
+	}
+}
+
+// WithTags shows subscription tags, cached in the file at path and fetched
+// in the background once stale.
+func WithTags(path string) Option {
+	return func(m *Model) {
+		m.tagsPath = path
+	}
+}
+
+// WithReadOnly disables switching. The client is wrapped so that any
+// mutating call fails with azure.ErrReadOnly.
+func WithReadOnly() Option {
+	return func(m *Model) {
+		m.readOnly = true
+		m.client = azure.NewReadOnlyClient(m.client)
+	}
+}
+
 // NewModel creates a new TUI model.
-func NewModel(client azure.Client) Model {
+func NewModel(client azure.Client, opts ...Option) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = SpinnerStyle
@@ -98,14 +342,34 @@ func NewModel(client azure.Client) Model {
 	h := help.New()
 	h.ShowAll = false
 
-	return Model{
-		client:  client,
-		state:   StateLoading,
-		view:    ViewSubscriptions,
-		spinner: s,
-		help:    h,
-		keys:    DefaultKeyMap(),
+	m := Model{
+		client:    client,
+		state:     StateLoading,
+		view:      ViewSubscriptions,
+		spinner:   s,
+		help:      h,
+		keys:      DefaultKeyMap(),
+		marking:   true,
+		input:     textinput.New(),
+		copyText:  copyToClipboard,
+		now:       time.Now,
+		sortOrder: config.SortName,
+		grouping:  config.GroupNone,
+		collapsed: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(&m)
 	}
+	m.keys.Clusters.SetEnabled(m.kube.Enabled && !m.readOnly && !m.picker)
+	m.keys.Select.SetEnabled(!m.readOnly)
+	m.keys.Mark.SetEnabled(m.marking)
+	m.keys.Actions.SetEnabled(!m.picker)
+	if m.picker {
+		m.keys.Select.SetHelp(m.keys.Select.Help().Key, "pick")
+	}
+	m.marks = make(map[string]bool)
+
+	return m
 }
 
 // Init initializes the model.
@@ -136,20 +400,94 @@ func (m Model) loadData() tea.Cmd {
 			return errMsg{err}
 		}
 
-		return dataLoadedMsg{
+		msg := dataLoadedMsg{
 			account:       account,
 			subscriptions: subs,
 			tenants:       tenants,
 		}
+		if m.historyPath != "" {
+			if h, err := history.Load(m.historyPath); err == nil {
+				msg.usage = h
+			}
+		}
+		// Cached tags are shown at once, however old; fetchTags refreshes them.
+		if m.tagsPath != "" {
+			if c, err := tags.Load(m.tagsPath); err == nil {
+				c.Apply(msg.subscriptions)
+			}
+		}
+		return msg
+	}
+}
+
+// subscriptionName returns the name of the subscription with the ID, or the
+// ID if it is not loaded.
+func (m Model) subscriptionName(id string) string {
+	for i := range m.allSubscriptions {
+		if m.allSubscriptions[i].ID == id {
+			return m.allSubscriptions[i].Name
+		}
+	}
+	return id
+}
+
+// checkTokens checks in the background the tokens of the tenants without a
+// status or whose token is about to expire. Other statuses are kept until
+// the tenant is switched to.
+func (m Model) checkTokens() tea.Cmd {
+	now := m.now()
+	var ids []string
+	for i := range m.tenants {
+		status, ok := m.tokenStatus[m.tenants[i].TenantID]
+		if !ok || !status.ExpiresOn.IsZero() && status.ExpiresOn.Sub(now) <= tokens.ExpiringWithin {
+			ids = append(ids, m.tenants[i].TenantID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return tokensCheckedMsg{statuses: tokens.CheckAll(context.Background(), m.client, ids, now)}
+	}
+}
+
+// fetchTags fetches the stale tags of the subscriptions in the background.
+func (m Model) fetchTags() tea.Cmd {
+	if m.tagsPath == "" || len(m.allSubscriptions) == 0 {
+		return nil
+	}
+
+	subs := slices.Clone(m.allSubscriptions)
+	return func() tea.Msg {
+		err := tags.Enrich(context.Background(), m.client, m.tagsPath, subs, tags.Options{TTL: m.tagsTTL})
+		fetched := make(map[string]map[string]string, len(subs))
+		for i := range subs {
+			fetched[subs[i].ID] = subs[i].Tags
+		}
+		return tagsFetchedMsg{tags: fetched, err: err}
 	}
 }
 
 // Update handles messages and updates the model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if next, ok := model.(Model); ok {
+		next.keepCursorVisible()
+		return next, cmd
+	}
+	return model, cmd
+}
+
+// update handles a message.
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
 
+	case tea.MouseMsg:
+		return m.handleMouseMsg(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -169,21 +507,107 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dataLoadedMsg:
 		m.state = StateReady
 		m.account = msg.account
-		m.subscriptions = msg.subscriptions
+		m.allSubscriptions = msg.subscriptions
+		m.usage = msg.usage
+		m.applyFilter()
 		m.tenants = msg.tenants
 		// Set cursor to current subscription
 		for i := range m.subscriptions {
 			if m.subscriptions[i].IsDefault {
-				m.cursor = i
+				m.moveCursorTo(m.subscriptions[i].ID)
 				break
 			}
 		}
+		return m, tea.Batch(m.checkTokens(), m.fetchTags())
+
+	case tagsFetchedMsg:
+		if msg.err != nil {
+			m.warning = fmt.Errorf("failed to fetch tags: %w", msg.err)
+		}
+		subs := slices.Clone(m.allSubscriptions)
+		for i := range subs {
+			if t, ok := msg.tags[subs[i].ID]; ok {
+				subs[i].Tags = t
+			}
+		}
+		m.allSubscriptions = subs
+		m.applyFilter()
+		return m, nil
+
+	case tokensCheckedMsg:
+		if m.tokenStatus == nil {
+			m.tokenStatus = make(map[string]tokens.Status, len(msg.statuses))
+		}
+		for _, status := range msg.statuses {
+			m.tokenStatus[status.TenantID] = status
+		}
 		return m, nil
 
 	case switchedMsg:
 		m.state = StateSuccess
 		m.message = msg.message
+		m.hookOutput = msg.hookOutput
+		m.hookErr = msg.hookErr
+		m.summary = m.switchSummary(msg)
+		// The switch may have logged in or refreshed the tenant's token.
+		delete(m.tokenStatus, msg.tenantID)
+		if m.quitAfterSwitch && !m.kube.Enabled {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.kube.Enabled && msg.subscriptionID != "" {
+			return m, tea.Batch(m.loadData(), m.loadClusters(msg.subscriptionID))
+		}
 		return m, m.loadData()
+
+	case clustersLoadedMsg:
+		if msg.err != nil {
+			m.warning = fmt.Errorf("failed to list AKS clusters: %w", msg.err)
+			return m, nil
+		}
+		m.clusters = msg.clusters
+		m.clusterSubscription = msg.subscriptionID
+		m.kubeContext = msg.kubeContext
+		m.clusterCursor = 0
+		if len(m.clusters) == 0 {
+			m.message = "No AKS clusters in " + m.subscriptionName(msg.subscriptionID)
+			if m.quitAfterSwitch {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		m.setView(ViewClusters)
+		return m, nil
+
+	case kubeContextSetMsg:
+		m.state = StateReady
+		m.setView(ViewSubscriptions)
+		m.kubeContext = msg.context
+		m.message = fmt.Sprintf("Kubernetes context switched to %s", msg.context)
+		m.summary = joinSummary(m.summary, "kubectl context "+msg.context)
+		if m.quitAfterSwitch {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case preHooksDoneMsg:
+		return m, m.loginTenant(msg.event, msg.output, msg.login)
+
+	case tenantLoggedInMsg:
+		return m, m.runPostHooks(msg.event, msg.output, "Directory switched successfully")
+
+	case actionDoneMsg:
+		m.state = StateReady
+		m.activity = ""
+		m.message = msg.message
+		m.warning = msg.err
+		m.actionOutput = msg.output
+		if msg.reload {
+			return m, m.loadData()
+		}
+		return m, nil
 	}
 
 	return m, nil
@@ -202,6 +626,14 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Prompts and the actions menu take all keys
+	if m.inputMode != inputNone {
+		return m.handleInputKey(msg)
+	}
+	if m.view == ViewActions {
+		return m.handleActionsKey(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		m.quitting = true
@@ -214,39 +646,72 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Tab):
 		if m.view == ViewSubscriptions {
-			m.view = ViewDirectories
+			m.setView(ViewDirectories)
 		} else {
-			m.view = ViewSubscriptions
+			m.setView(ViewSubscriptions)
 		}
 		return m, nil
 
-	case key.Matches(msg, m.keys.Up):
-		if m.view == ViewSubscriptions {
-			if m.cursor > 0 {
-				m.cursor--
-			}
-		} else {
-			if m.tenantCursor > 0 {
-				m.tenantCursor--
+	case key.Matches(msg, m.keys.Back):
+		if m.view == ViewClusters {
+			if m.quitAfterSwitch {
+				m.quitting = true
+				return m, tea.Quit
 			}
+			m.setView(ViewSubscriptions)
 		}
 		return m, nil
 
+	case key.Matches(msg, m.keys.Up):
+		m.moveCursor(-1)
+		return m, nil
+
 	case key.Matches(msg, m.keys.Down):
-		if m.view == ViewSubscriptions {
-			if m.cursor < len(m.subscriptions)-1 {
-				m.cursor++
-			}
-		} else {
-			if m.tenantCursor < len(m.tenants)-1 {
-				m.tenantCursor++
-			}
-		}
+		m.moveCursor(1)
 		return m, nil
 
-	case key.Matches(msg, m.keys.Select):
+	case key.Matches(msg, m.keys.Clusters):
+		if m.account == nil {
+			return m, nil
+		}
+		m.warning = nil
+		return m, m.loadClusters(m.account.ID)
+
+	// Select is disabled in read-only mode, but still expands and collapses groups
+	case matchesKeys(msg, m.keys.Select):
 		return m.handleSelect()
 
+	case key.Matches(msg, m.keys.Mark):
+		if id := m.currentID(); id != "" && (m.view == ViewSubscriptions || m.picker) {
+			m.toggleMark(id)
+			m.moveCursor(1)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Filter):
+		m.setView(ViewSubscriptions)
+		return m, m.startInput(inputFilter, "/ ", m.filterText)
+
+	case key.Matches(msg, m.keys.Actions):
+		m.openActions()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Sort):
+		m.setView(ViewSubscriptions)
+		return m.cycleSort()
+
+	case key.Matches(msg, m.keys.Group):
+		m.setView(ViewSubscriptions)
+		return m.cycleGroup()
+
+	case key.Matches(msg, m.keys.Expand):
+		m.setExpanded(true)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Collapse):
+		m.setExpanded(false)
+		return m, nil
+
 	case key.Matches(msg, m.keys.Refresh):
 		m.state = StateLoading
 		return m, tea.Batch(m.spinner.Tick, m.loadData())
@@ -255,119 +720,634 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// handleSelect handles the selection.
+// moveCursor moves the cursor of the current view by delta, staying within bounds.
+func (m *Model) moveCursor(delta int) {
+	cursor, n := m.currentCursor()
+	if cursor == nil {
+		return
+	}
+
+	next := *cursor + delta
+	if next >= 0 && next < n {
+		*cursor = next
+	}
+}
+
+// currentCursor returns the cursor of the current view and the number of
+// items it moves over, or nil if the view has no cursor.
+func (m *Model) currentCursor() (cursor *int, n int) {
+	switch m.view {
+	case ViewSubscriptions:
+		return &m.cursor, len(m.rows())
+	case ViewDirectories:
+		return &m.tenantCursor, len(m.dirRows())
+	case ViewClusters:
+		return &m.clusterCursor, len(m.clusters)
+	case ViewActions:
+		return &m.actionCursor, len(m.actions)
+	}
+	return nil, 0
+}
+
+// cursorPosition returns the cursor of the current view, or -1 if it has none.
+func (m Model) cursorPosition() int {
+	if cursor, _ := m.currentCursor(); cursor != nil {
+		return *cursor
+	}
+	return -1
+}
+
+// setView shows another view, scrolled to its cursor.
+func (m *Model) setView(view ViewType) {
+	m.view = view
+	m.offset = 0
+}
+
+// keepCursorVisible scrolls the current list so that its cursor is shown.
+func (m *Model) keepCursorVisible() {
+	m.offset, _ = m.frame().window(m.offset, m.cursorPosition())
+}
+
+// handleSelect handles the selection. On a group header it expands or
+// collapses the group, even in read-only and picker mode.
 func (m Model) handleSelect() (tea.Model, tea.Cmd) {
-	if m.view == ViewSubscriptions && len(m.subscriptions) > 0 {
-		sub := m.subscriptions[m.cursor]
+	if row, ok := m.selectedRow(); ok && m.view == ViewSubscriptions && row.group != nil {
+		m.toggleGroup(row.group)
+		return m, nil
+	}
+	if m.readOnly {
+		return m, nil
+	}
+	if m.picker {
+		return m.pick()
+	}
+
+	if m.view == ViewClusters && len(m.clusters) > 0 {
+		cluster := m.clusters[m.clusterCursor]
+		m.state = StateSwitching
+		m.clearStatus()
+		return m, tea.Batch(
+			m.spinner.Tick,
+			m.useCluster(cluster),
+		)
+	} else if sub := m.selectedSubscription(); m.view == ViewSubscriptions && sub != nil {
 		if sub.IsDefault {
 			// Already selected
 			return m, nil
 		}
 		m.state = StateSwitching
+		m.clearStatus()
 		return m, tea.Batch(
 			m.spinner.Tick,
-			m.switchSubscription(sub.ID),
+			m.switchSubscription(*sub),
 		)
-	} else if m.view == ViewDirectories && len(m.tenants) > 0 {
-		tenant := m.tenants[m.tenantCursor]
+	} else if row, ok := m.selectedDirRow(); m.view == ViewDirectories && ok {
+		if row.sub != nil && row.sub.IsDefault {
+			// Already selected
+			return m, nil
+		}
 		m.state = StateSwitching
+		m.clearStatus()
+		if row.sub != nil {
+			return m, tea.Batch(
+				m.spinner.Tick,
+				m.switchSubscription(*row.sub),
+			)
+		}
 		return m, tea.Batch(
 			m.spinner.Tick,
-			m.switchTenant(tenant.TenantID),
+			m.switchTenant(row.tenant.TenantID),
 		)
 	}
 	return m, nil
 }
 
-// switchSubscription switches to the specified subscription.
-func (m Model) switchSubscription(id string) tea.Cmd {
+// clearStatus clears the result of the previous switch.
+func (m *Model) clearStatus() {
+	m.message = ""
+	m.warning = nil
+	m.hookOutput = ""
+	m.hookErr = nil
+	m.actionOutput = ""
+}
+
+// switchSubscription runs the pre-switch hooks, switches to the subscription
+// and runs the post-switch hooks. A subscription in a tenant without a valid
+// token is switched to after an interactive login to the tenant.
+func (m Model) switchSubscription(sub azure.Subscription) tea.Cmd {
+	ev := hooks.Event{
+		Kind:                hooks.KindSubscription,
+		NewSubscriptionID:   sub.ID,
+		NewSubscriptionName: sub.Name,
+		NewTenantID:         sub.TenantID,
+	}.WithOld(m.account)
+
+	crossTenant := m.account != nil && sub.TenantID != "" && sub.TenantID != m.account.TenantID
+
 	return func() tea.Msg {
 		ctx := context.Background()
-		if err := m.client.SetSubscription(ctx, id); err != nil {
+
+		preOutput, err := m.hooks.Pre(ctx, ev)
+		if err != nil {
+			return errMsg{withOutput(err, preOutput)}
+		}
+
+		// Another tenant may need a login first. Only a definite auth
+		// failure triggers one; anything else is left to the switch itself.
+		if crossTenant {
+			if _, err := m.client.GetAccessToken(ctx, sub.TenantID); errors.Is(err, azure.ErrAuthRequired) {
+				return preHooksDoneMsg{event: ev, output: preOutput}
+			}
+		}
+
+		if err := m.client.SetSubscription(ctx, sub.ID); err != nil {
 			return errMsg{err}
 		}
-		return switchedMsg{message: "Subscription switched successfully"}
+		// A failure to record the switch only affects the usage sort orders.
+		if m.historyPath != "" {
+			_ = history.Record(m.historyPath, sub.ID)
+		}
+
+		postOutput, err := m.hooks.Post(ctx, ev)
+		return switchedMsg{
+			message:        "Subscription switched successfully",
+			subscriptionID: sub.ID,
+			tenantID:       sub.TenantID,
+			hookOutput:     joinOutput(preOutput, postOutput),
+			hookErr:        err,
+		}
+	}
+}
+
+// switchUser runs the pre-switch hooks and switches to the subscription with
+// another signed-in user's credentials. Without a token of that user for the
+// tenant, the tenant is logged in to interactively and the subscription is
+// switched to after the login.
+func (m Model) switchUser(sub azure.Subscription, user azure.User) tea.Cmd {
+	ev := hooks.Event{
+		Kind:                hooks.KindSubscription,
+		NewSubscriptionID:   sub.ID,
+		NewSubscriptionName: sub.Name,
+		NewTenantID:         sub.TenantID,
+	}.WithOld(m.account)
+
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		preOutput, err := m.hooks.Pre(ctx, ev)
+		if err != nil {
+			return errMsg{withOutput(err, preOutput)}
+		}
+
+		if err := azure.SwitchUser(ctx, m.client, sub, user); err != nil {
+			if errors.Is(err, azure.ErrAuthRequired) {
+				return preHooksDoneMsg{event: ev, output: preOutput}
+			}
+			return errMsg{withOutput(err, preOutput)}
+		}
+		if m.historyPath != "" {
+			_ = history.Record(m.historyPath, sub.ID)
+		}
+		return m.finishSwitch(ctx, ev, preOutput, fmt.Sprintf("Switched to %s as %s", sub.Name, user.Name))
 	}
 }
 
-// switchTenant switches to the specified tenant using interactive login.
+// switchTenant runs the pre-switch hooks and switches to the most recently
+// used subscription of the tenant. An interactive login to the tenant starts
+// only if it has no valid token or no subscriptions; tenants without
+// subscriptions are logged in to at tenant level.
 func (m Model) switchTenant(id string) tea.Cmd {
-	cmd := exec.Command("az", "login", "--tenant", id)
-	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+	ev := hooks.Event{
+		Kind:        hooks.KindTenant,
+		NewTenantID: id,
+	}.WithOld(m.account)
+
+	candidates := listing.Sort(m.allSubscriptions, config.SortRecent, m.usage)
+
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		output, err := m.hooks.Pre(ctx, ev)
+		if err != nil {
+			return errMsg{withOutput(err, output)}
+		}
+
+		sub, err := azure.SwitchToTenant(ctx, m.client, id, candidates)
+		if azure.NeedsLogin(err) {
+			login := azure.LoginOptions{AllowNoSubscriptions: errors.Is(err, azure.ErrNoSubscriptions)}
+			return preHooksDoneMsg{event: ev, output: output, login: login}
+		}
+		if err != nil {
+			return errMsg{withOutput(err, output)}
+		}
+
+		ev.NewSubscriptionID = sub.ID
+		ev.NewSubscriptionName = sub.Name
+		if m.historyPath != "" {
+			_ = history.Record(m.historyPath, sub.ID)
+		}
+		return m.finishSwitch(ctx, ev, output, fmt.Sprintf("Directory switched to %s without a new login", sub.Name))
+	}
+}
+
+// loginTenant switches to the event's tenant using interactive login.
+func (m Model) loginTenant(ev hooks.Event, preOutput string, opts azure.LoginOptions) tea.Cmd {
+	if m.readOnly {
+		return func() tea.Msg { return errMsg{azure.ErrReadOnly} }
+	}
+	login := &loginExec{client: m.client, tenantID: ev.NewTenantID, opts: opts}
+	return tea.Exec(login, func(err error) tea.Msg {
 		if err != nil {
 			return errMsg{err}
 		}
-		return switchedMsg{message: "Directory switched successfully"}
+		return tenantLoggedInMsg{event: ev, output: preOutput}
 	})
 }
 
+// loginExec runs an interactive tenant login through the client on the
+// terminal Bubble Tea hands over.
+type loginExec struct {
+	client   azure.Client
+	tenantID string
+	opts     azure.LoginOptions
+}
+
+// Run implements tea.ExecCommand.
+func (l *loginExec) Run() error {
+	return l.client.LoginToTenant(context.Background(), l.tenantID, l.opts)
+}
+
+// SetStdin implements tea.ExecCommand.
+func (l *loginExec) SetStdin(r io.Reader) { l.opts.Stdin = r }
+
+// SetStdout implements tea.ExecCommand.
+func (l *loginExec) SetStdout(w io.Writer) { l.opts.Stdout = w }
+
+// SetStderr implements tea.ExecCommand.
+func (l *loginExec) SetStderr(w io.Writer) { l.opts.Stderr = w }
+
+// runPostHooks runs the post-switch hooks once the new account is known. If
+// the event names a subscription, it is switched to first.
+func (m Model) runPostHooks(ev hooks.Event, preOutput, message string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		if id := ev.NewSubscriptionID; id != "" {
+			if err := m.client.SetSubscription(ctx, id); err != nil {
+				return errMsg{withOutput(err, preOutput)}
+			}
+			if m.historyPath != "" {
+				_ = history.Record(m.historyPath, id)
+			}
+			message = "Subscription switched successfully"
+		}
+		return m.finishSwitch(ctx, ev, preOutput, message)
+	}
+}
+
+// finishSwitch runs the post-switch hooks once the new account is known.
+func (m Model) finishSwitch(ctx context.Context, ev hooks.Event, preOutput, message string) tea.Msg {
+	subscriptionID := ev.NewSubscriptionID
+	if account, err := m.client.GetCurrentAccount(ctx); err == nil {
+		ev = ev.WithNew(account)
+	}
+
+	postOutput, err := m.hooks.Post(ctx, ev)
+	return switchedMsg{
+		message:        message,
+		subscriptionID: subscriptionID,
+		tenantID:       ev.NewTenantID,
+		hookOutput:     joinOutput(preOutput, postOutput),
+		hookErr:        err,
+	}
+}
+
+// kubeconfigPath returns the kubeconfig file the AKS integration updates.
+func (m Model) kubeconfigPath() (string, error) {
+	if m.kube.Kubeconfig != "" {
+		return config.ExpandHome(m.kube.Kubeconfig), nil
+	}
+	return kubeconfig.DefaultPath()
+}
+
+// loadClusters loads the AKS clusters of a subscription and the current kubectl context.
+func (m Model) loadClusters(subscriptionID string) tea.Cmd {
+	return func() tea.Msg {
+		clusters, err := m.client.ListAKSClusters(context.Background(), subscriptionID)
+		if err != nil {
+			return clustersLoadedMsg{err: err}
+		}
+
+		msg := clustersLoadedMsg{subscriptionID: subscriptionID, clusters: clusters}
+		if path, err := m.kubeconfigPath(); err == nil {
+			if kc, err := kubeconfig.Load(path); err == nil {
+				msg.kubeContext = kc.CurrentContext()
+			}
+		}
+		return msg
+	}
+}
+
+// useCluster makes the cluster's kubeconfig context current. A context mapped
+// in the config or already present in the kubeconfig is reused; otherwise the
+// credentials are fetched with az aks get-credentials.
+func (m Model) useCluster(cluster azure.AKSCluster) tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.kubeconfigPath()
+		if err != nil {
+			return errMsg{err}
+		}
+
+		kc, err := kubeconfig.Load(path)
+		if err != nil {
+			return errMsg{err}
+		}
+
+		name, ok := m.kube.Contexts[cluster.Name]
+		if !ok {
+			if c, found := kc.FindContext(cluster.Name); found {
+				name, ok = c.Name, true
+			}
+		}
+		if ok {
+			if err := kc.SetCurrentContext(name); err == nil {
+				if err := kc.Save(); err != nil {
+					return errMsg{err}
+				}
+				return kubeContextSetMsg{context: name}
+			}
+		}
+
+		if err := m.client.GetAKSCredentials(context.Background(), cluster, path); err != nil {
+			return errMsg{err}
+		}
+
+		name = cluster.Name
+		if kc, err := kubeconfig.Load(path); err == nil && kc.CurrentContext() != "" {
+			name = kc.CurrentContext()
+		}
+		return kubeContextSetMsg{context: name}
+	}
+}
+
+// withOutput appends hook output to err.
+func withOutput(err error, output string) error {
+	if output == "" {
+		return err
+	}
+	return fmt.Errorf("%w\n%s", err, output)
+}
+
+// joinOutput joins non-empty hook outputs.
+func joinOutput(outputs ...string) string {
+	var parts []string
+	for _, o := range outputs {
+		if o != "" {
+			parts = append(parts, o)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
 // View renders the UI.
 func (m Model) View() string {
 	if m.quitting {
+		if m.inline > 0 && !m.picker {
+			return m.renderSummary() + "\n"
+		}
 		return ""
 	}
 
-	var s strings.Builder
+	f := m.frame()
+	start, end := f.window(m.offset, m.cursorPosition())
+	return f.top + strings.Join(f.items[start:end], "") + f.bottom
+}
+
+// frame is the rendered screen split around the items of the current list,
+// so that the list can be windowed to the terminal height and hit-tested.
+type frame struct {
+	// top is everything above the items: header, tabs and list heading.
+	top string
+
+	// items holds one rendered block per list item, each ending in a newline.
+	items []string
 
-	// Header with current account
-	s.WriteString(m.renderHeader())
-	s.WriteString("\n")
+	// bottom is everything below the items: list footer, status and help.
+	bottom string
+
+	// tabs are the clickable tabs on screen line tabsLine, which is -1 when
+	// no tabs are shown.
+	tabs     []tabSpan
+	tabsLine int
+
+	// height is the number of lines available to the items, or 0 for no limit.
+	height int
+}
+
+// frame renders the screen for the current state.
+func (m Model) frame() frame {
+	if m.inline > 0 {
+		return m.inlineFrame()
+	}
+
+	f := frame{tabsLine: -1}
+	var top, bottom strings.Builder
 
 	// Main content
 	switch m.state {
 	case StateLoading:
-		s.WriteString(m.renderLoading())
+		top.WriteString(m.renderLoading())
 	case StateError:
-		s.WriteString(m.renderError())
+		top.WriteString(m.renderError())
 	case StateSwitching:
-		s.WriteString(m.renderSwitching())
+		top.WriteString(m.renderSwitching())
 	default:
-		s.WriteString(m.renderTabs())
-		s.WriteString("\n")
-		if m.view == ViewSubscriptions {
-			s.WriteString(m.renderSubscriptions())
-		} else {
-			s.WriteString(m.renderDirectories())
+		f.tabsLine = strings.Count(top.String(), "\n")
+		tabs, spans := m.renderTabs()
+		f.tabs = spans
+		top.WriteString(tabs)
+		top.WriteString("\n")
+
+		var heading, footer string
+		switch m.view {
+		case ViewSubscriptions:
+			heading, f.items = m.renderSubscriptions()
+		case ViewDirectories:
+			heading, f.items = m.renderDirectories()
+		case ViewClusters:
+			heading, f.items, footer = m.renderClusters()
+		case ViewActions:
+			heading, f.items, footer = m.renderActions()
 		}
+		top.WriteString(heading)
+		bottom.WriteString(footer)
+		bottom.WriteString(m.renderStatus())
 	}
 
 	// Help
-	s.WriteString("\n")
-	s.WriteString(HelpStyle.Render(m.help.View(m.keys)))
+	bottom.WriteString("\n")
+	bottom.WriteString(HelpStyle.Render(m.help.View(m.keys)))
+
+	// Header with current account, on one line when the boxed header would
+	// leave no room for the list.
+	header := m.renderHeader() + "\n"
+	f.bottom = bottom.String()
+	if m.height > 0 && strings.Count(header+top.String()+f.bottom, "\n")+minListHeight >= m.height {
+		header = m.renderCompactHeader() + "\n"
+	}
+	f.top = header + top.String()
+	if f.tabsLine >= 0 {
+		f.tabsLine += strings.Count(header, "\n")
+	}
 
-	return s.String()
+	if m.height > 0 {
+		f.height = max(m.height-strings.Count(f.top, "\n")-strings.Count(f.bottom, "\n")-1, 1)
+	}
+	return f
+}
+
+// minListHeight is the number of list lines below which the header is
+// rendered compactly.
+const minListHeight = 4
+
+// window returns the range of items to show, starting at offset but moved
+// just enough to keep the cursor visible.
+func (f frame) window(offset, cursor int) (start, end int) {
+	n := len(f.items)
+	if f.height <= 0 || n == 0 {
+		return 0, n
+	}
+
+	start = min(max(offset, 0), n-1)
+	if cursor >= 0 && cursor < start {
+		start = cursor
+	}
+	end = f.fill(start)
+	for cursor >= end && start < cursor {
+		start++
+		end = f.fill(start)
+	}
+	return start, end
+}
+
+// fill returns the end of the items that fit from start, always at least one.
+func (f frame) fill(start int) int {
+	used, end := 0, start
+	for end < len(f.items) {
+		h := strings.Count(f.items[end], "\n")
+		if end > start && used+h > f.height {
+			break
+		}
+		used += h
+		end++
+	}
+	return end
+}
+
+// lastStart returns the window start that shows the last item at the bottom.
+func (f frame) lastStart() int {
+	used := 0
+	for i := len(f.items) - 1; i >= 0; i-- {
+		used += strings.Count(f.items[i], "\n")
+		if used > f.height {
+			return min(i+1, len(f.items)-1)
+		}
+	}
+	return 0
+}
+
+// renderTitle renders the application title in style.
+func (m Model) renderTitle(style lipgloss.Style) string {
+	title := style.Render("Azure Account Switcher")
+	if m.readOnly {
+		title = lipgloss.JoinHorizontal(lipgloss.Top, title, " ", ReadOnlyBadgeStyle.Render("READ-ONLY"))
+	}
+	return title
+}
+
+// renderCompactHeader renders the title and the current subscription on one
+// line, for windows too short for the boxed header.
+func (m Model) renderCompactHeader() string {
+	title := m.renderTitle(TitleStyle.UnsetMarginBottom())
+	if m.account == nil {
+		return title
+	}
+
+	name := CurrentStyle
+	if accent, ok := AccentColor(m.accents, m.account.ID, m.account.Name); ok {
+		name = name.Foreground(accent)
+	}
+	return title + MutedStyle.Render(" · ") + name.Render(m.account.Name)
 }
 
 // renderHeader renders the header section.
 func (m Model) renderHeader() string {
+	title := m.renderTitle(TitleStyle)
+
 	if m.account == nil {
-		return TitleStyle.Render("Azure Account Switcher")
+		return title
 	}
 
 	var content strings.Builder
-	content.WriteString(TitleStyle.Render("Azure Account Switcher"))
+	content.WriteString(title)
 	content.WriteString("\n")
 	content.WriteString(fmt.Sprintf("  %s %s\n", MutedStyle.Render("User:"), m.account.User.Name))
 	content.WriteString(fmt.Sprintf("  %s %s\n", MutedStyle.Render("Tenant:"), m.account.TenantDisplayName))
-	content.WriteString(fmt.Sprintf("  %s %s", MutedStyle.Render("Subscription:"), CurrentStyle.Render(m.account.Name)))
+	box, name := HeaderBoxStyle, CurrentStyle
+	if accent, ok := AccentColor(m.accents, m.account.ID, m.account.Name); ok {
+		box = box.BorderForeground(accent)
+		name = name.Foreground(accent)
+	}
+	content.WriteString(fmt.Sprintf("  %s %s", MutedStyle.Render("Subscription:"), name.Render(m.account.Name)))
 
-	return HeaderBoxStyle.Render(content.String())
+	return box.Render(content.String())
 }
 
-// renderTabs renders the tab bar.
-func (m Model) renderTabs() string {
-	subsTab := "Subscriptions"
-	dirsTab := "Directories"
+// tabSpan is the columns [start, end) of a tab in the tab bar.
+type tabSpan struct {
+	start, end int
+	view       ViewType
+}
 
-	if m.view == ViewSubscriptions {
-		subsTab = ActiveTabStyle.Render(subsTab)
-		dirsTab = InactiveTabStyle.Render(dirsTab)
-	} else {
-		subsTab = InactiveTabStyle.Render(subsTab)
-		dirsTab = ActiveTabStyle.Render(dirsTab)
+// renderTabs renders the tab bar and returns the columns of each tab.
+func (m Model) renderTabs() (string, []tabSpan) {
+	tabs := []struct {
+		label string
+		view  ViewType
+	}{
+		{"Subscriptions", ViewSubscriptions},
+		{"Directories", ViewDirectories},
+	}
+	switch m.view {
+	case ViewClusters:
+		tabs = append(tabs, struct {
+			label string
+			view  ViewType
+		}{"AKS Clusters", ViewClusters})
+	case ViewActions:
+		tabs = append(tabs, struct {
+			label string
+			view  ViewType
+		}{"Actions", ViewActions})
 	}
 
-	return fmt.Sprintf("  %s  |  %s", subsTab, dirsTab)
+	var s strings.Builder
+	s.WriteString("  ")
+	spans := make([]tabSpan, 0, len(tabs))
+	for i, tab := range tabs {
+		if i > 0 {
+			s.WriteString("  |  ")
+		}
+		style := InactiveTabStyle
+		if tab.view == m.view {
+			style = ActiveTabStyle
+		}
+		start := lipgloss.Width(s.String())
+		s.WriteString(style.Render(tab.label))
+		spans = append(spans, tabSpan{start: start, end: lipgloss.Width(s.String()), view: tab.view})
+	}
+
+	return s.String(), spans
 }
 
 // renderLoading renders the loading state.
@@ -375,9 +1355,13 @@ func (m Model) renderLoading() string {
 	return fmt.Sprintf("\n  %s Loading...", m.spinner.View())
 }
 
-// renderSwitching renders the switching state.
+// renderSwitching renders the switching state, or the action in progress.
 func (m Model) renderSwitching() string {
-	return fmt.Sprintf("\n  %s Switching...", m.spinner.View())
+	activity := "Switching..."
+	if m.activity != "" {
+		activity = m.activity
+	}
+	return fmt.Sprintf("\n  %s %s", m.spinner.View(), activity)
 }
 
 // renderError renders the error state.
@@ -385,22 +1369,62 @@ func (m Model) renderError() string {
 	return fmt.Sprintf("\n  %s %s", ErrorStyle.Render("Error:"), m.err.Error())
 }
 
-// renderSubscriptions renders the subscriptions list.
-func (m Model) renderSubscriptions() string {
-	if len(m.subscriptions) == 0 {
-		return MutedStyle.Render("\n  No subscriptions found")
+// renderStatus renders the result of the last switch, including hook output.
+func (m Model) renderStatus() string {
+	if m.message == "" && m.warning == nil && m.hookOutput == "" && m.hookErr == nil && m.actionOutput == "" {
+		return ""
 	}
 
 	var s strings.Builder
-	s.WriteString("\n")
+	if m.message != "" {
+		s.WriteString(fmt.Sprintf("\n  %s\n", SuccessStyle.Render(m.message)))
+	}
+	if m.warning != nil {
+		s.WriteString(fmt.Sprintf("\n  %s %s\n", WarningStyle.Render("Warning:"), m.warning.Error()))
+	}
+	if m.hookErr != nil {
+		s.WriteString(fmt.Sprintf("\n  %s %s\n", WarningStyle.Render("Hook failed:"), m.hookErr.Error()))
+	}
+	for _, output := range []string{m.hookOutput, m.actionOutput} {
+		if output == "" {
+			continue
+		}
+		s.WriteString("\n")
+		for _, line := range strings.Split(output, "\n") {
+			s.WriteString(fmt.Sprintf("  %s %s\n", MutedStyle.Render("│"), line))
+		}
+	}
+
+	return s.String()
+}
 
-	for i := range m.subscriptions {
-		sub := &m.subscriptions[i]
+// renderSubscriptions renders the heading and items of the subscriptions list.
+func (m Model) renderSubscriptions() (string, []string) {
+	heading := "\n" + m.renderFilterLine()
+	if len(m.subscriptions) == 0 {
+		if len(m.allSubscriptions) > 0 {
+			return heading + MutedStyle.Render("  No subscriptions match the filter"), nil
+		}
+		return MutedStyle.Render("\n  No subscriptions found"), nil
+	}
+
+	// Users are only told apart once more than one is signed in.
+	showUsers := len(azure.Users(m.allSubscriptions)) > 1
+
+	rows := m.rows()
+	items := make([]string, 0, len(rows))
+	for i, row := range rows {
 		cursor := "  "
 		if i == m.cursor {
 			cursor = CursorStyle.Render("> ")
 		}
 
+		if row.group != nil {
+			items = append(items, fmt.Sprintf("%s%s\n", cursor, renderGroupHeader(row, i == m.cursor)))
+			continue
+		}
+
+		sub := row.sub
 		name := sub.Name
 		switch {
 		case sub.IsDefault:
@@ -410,68 +1434,117 @@ func (m Model) renderSubscriptions() string {
 		default:
 			name = NormalStyle.Render(name)
 		}
+		if m.isFavorite(sub) {
+			name = FavoriteStyle.Render("★ ") + name
+		}
+		if alias := m.aliasOf(sub); alias != "" {
+			name += " " + MutedStyle.Render("("+alias+")")
+		}
+		if badges := m.tagBadges(sub); badges != "" {
+			name += "  " + badges
+		}
 
-		s.WriteString(fmt.Sprintf("%s%s\n", cursor, name))
-		s.WriteString(fmt.Sprintf("    %s\n", MutedStyle.Render(sub.ID)))
+		detail := sub.ID
+		if showUsers && sub.User.Name != "" {
+			detail += " · " + sub.User.Name
+		}
+		items = append(items, fmt.Sprintf("%s%s%s\n    %s\n", cursor, m.markColumn(sub.ID), name, MutedStyle.Render(detail)))
 	}
 
-	return s.String()
+	return heading, items
 }
 
-// renderDirectories renders the directories (tenants) list with their subscriptions.
-func (m Model) renderDirectories() string {
+// renderDirectories renders the heading and items of the directories
+// (tenants) list, each item listing the tenant's subscriptions.
+func (m Model) renderDirectories() (string, []string) {
 	if len(m.tenants) == 0 {
-		return MutedStyle.Render("\n  No directories found")
+		return MutedStyle.Render("\n  No directories found"), nil
 	}
 
-	// Group subscriptions by tenant ID
-	subsByTenant := make(map[string][]azure.Subscription)
-	for i := range m.subscriptions {
-		sub := &m.subscriptions[i]
-		subsByTenant[sub.TenantID] = append(subsByTenant[sub.TenantID], *sub)
+	heading := MutedStyle.Render("  Enter switches directory or subscription, logging in only if needed")
+	if m.readOnly {
+		heading = WarningStyle.Render("  Read-only mode: directories cannot be switched")
 	}
 
-	var s strings.Builder
-	s.WriteString("\n")
-	s.WriteString(WarningStyle.Render("  ⚠ Switching directories will open browser for re-authentication"))
-	s.WriteString("\n\n")
+	rows := m.dirRows()
+	items := make([]string, 0, len(rows))
+	for i, row := range rows {
+		items = append(items, m.renderDirRow(row, i == m.tenantCursor))
+	}
 
-	for i := range m.tenants {
-		tenant := &m.tenants[i]
+	return "\n" + heading + "\n\n", items
+}
+
+// renderClusters renders the heading, items and footer of the AKS clusters
+// of the subscription last switched to.
+func (m Model) renderClusters() (heading string, items []string, footer string) {
+	heading = fmt.Sprintf("\n  %s %s\n\n", MutedStyle.Render("Pick a kubectl context for"), m.subscriptionName(m.clusterSubscription))
+
+	items = make([]string, 0, len(m.clusters))
+	for i := range m.clusters {
+		cluster := &m.clusters[i]
 		cursor := "  "
-		if i == m.tenantCursor {
+		if i == m.clusterCursor {
 			cursor = CursorStyle.Render("> ")
 		}
 
-		name := tenant.Title()
-		isCurrent := m.account != nil && tenant.TenantID == m.account.TenantID
+		name := cluster.Name
+		isCurrent := m.kubeContext != "" && strings.EqualFold(m.kubeContext, m.contextFor(cluster.Name))
 
 		switch {
 		case isCurrent:
 			name = CurrentStyle.Render(name + " ✓")
-		case i == m.tenantCursor:
+		case i == m.clusterCursor:
 			name = SelectedStyle.Render(name)
 		default:
 			name = NormalStyle.Render(name)
 		}
 
-		s.WriteString(fmt.Sprintf("%s%s\n", cursor, name))
+		items = append(items, fmt.Sprintf("%s%s\n    %s\n", cursor, name, MutedStyle.Render(cluster.ResourceGroup+" · "+cluster.Location)))
+	}
 
-		// Show subscriptions for this directory
-		if subs, ok := subsByTenant[tenant.TenantID]; ok && len(subs) > 0 {
-			for j := range subs {
-				subName := subs[j].Name
-				if subs[j].IsDefault {
-					subName = CurrentStyle.Render("• " + subName)
-				} else {
-					subName = MutedStyle.Render("• " + subName)
-				}
-				s.WriteString(fmt.Sprintf("    %s\n", subName))
-			}
-		} else {
-			s.WriteString(fmt.Sprintf("    %s\n", MutedStyle.Render("(no subscriptions)")))
+	footer = fmt.Sprintf("\n  %s\n", MutedStyle.Render("esc to skip"))
+	return heading, items, footer
+}
+
+// contextFor returns the kubeconfig context name expected for a cluster.
+func (m Model) contextFor(clusterName string) string {
+	if name, ok := m.kube.Contexts[clusterName]; ok {
+		return name
+	}
+	return clusterName
+}
+
+// isFavorite reports whether the subscription is listed in the favorites.
+func (m Model) isFavorite(sub *azure.Subscription) bool {
+	for _, f := range m.favorites {
+		if strings.EqualFold(f, sub.ID) || strings.EqualFold(f, sub.Name) {
+			return true
 		}
 	}
+	return false
+}
 
-	return s.String()
+// tagBadges renders the subscription's tags shown as badges, or "" if it
+// has none.
+func (m Model) tagBadges(sub *azure.Subscription) string {
+	pairs := tags.Format(sub.Tags, m.tagNames)
+	badges := make([]string, len(pairs))
+	for i, pair := range pairs {
+		badges[i] = TagStyle.Render("[" + pair + "]")
+	}
+	return strings.Join(badges, " ")
+}
+
+// aliasOf returns the configured alias of the subscription, if any.
+func (m Model) aliasOf(sub *azure.Subscription) string {
+	var found string
+	for alias, target := range m.aliases {
+		if strings.EqualFold(target, sub.ID) || strings.EqualFold(target, sub.Name) {
+			if found == "" || alias < found {
+				found = alias
+			}
+		}
+	}
+	return found
 }
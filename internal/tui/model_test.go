@@ -1,11 +1,21 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/hooks"
 )
 
 func TestNewModel(t *testing.T) {
@@ -252,3 +262,567 @@ func TestModel_HelpToggle(t *testing.T) {
 		t.Error("expected showHelp to be false after pressing ? again")
 	}
 }
+
+func TestModel_SwitchSubscription_PreHookVeto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook uses POSIX shell syntax")
+	}
+
+	client := azure.NewMockClient()
+	runner := hooks.NewRunner(config.Hooks{
+		PreSwitch: []config.Hook{{Command: "echo blocked; exit 1"}},
+	})
+	model := NewModel(client, WithHooks(runner))
+
+	msg := model.switchSubscription(azure.Subscription{ID: "id-2", Name: "Sub 2"})()
+
+	em, ok := msg.(errMsg)
+	if !ok {
+		t.Fatalf("expected errMsg, got %T", msg)
+	}
+
+	if !errors.Is(em.err, hooks.ErrVetoed) {
+		t.Errorf("expected ErrVetoed, got %v", em.err)
+	}
+
+	if !strings.Contains(em.err.Error(), "blocked") {
+		t.Errorf("expected hook output in error, got %q", em.err.Error())
+	}
+
+	if len(client.Calls.SetSubscription) != 0 {
+		t.Error("expected SetSubscription not to be called after veto")
+	}
+}
+
+func TestModel_SwitchSubscription_HookOutputShown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook uses POSIX shell syntax")
+	}
+
+	client := azure.NewMockClient()
+	runner := hooks.NewRunner(config.Hooks{
+		PostSwitch: []config.Hook{{Command: "echo kubeconfig updated"}},
+	})
+	model := NewModel(client, WithHooks(runner))
+	model.state = StateReady
+
+	msg := model.switchSubscription(azure.Subscription{ID: "id-2", Name: "Sub 2"})()
+	newModel, _ := model.Update(msg)
+	m := newModel.(Model)
+
+	if m.hookOutput != "kubeconfig updated" {
+		t.Errorf("expected hook output to be recorded, got %q", m.hookOutput)
+	}
+
+	m.state = StateReady
+	if !strings.Contains(m.View(), "kubeconfig updated") {
+		t.Error("expected hook output in view")
+	}
+}
+
+func TestModel_ClustersLoaded_OpensClusterView(t *testing.T) {
+	client := azure.NewMockClient()
+	model := NewModel(client, WithKubernetes(config.Kubernetes{Enabled: true}))
+	model.state = StateReady
+
+	msg := model.loadClusters("sub-id")()
+	newModel, _ := model.Update(msg)
+	m := newModel.(Model)
+
+	if m.view != ViewClusters {
+		t.Fatalf("expected view to be ViewClusters, got %v", m.view)
+	}
+
+	if len(m.clusters) != 1 || m.clusters[0].Name != "test-aks" {
+		t.Errorf("unexpected clusters: %+v", m.clusters)
+	}
+
+	// Esc leaves the cluster picker.
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+
+	if m.view != ViewSubscriptions {
+		t.Errorf("expected view to be ViewSubscriptions after esc, got %v", m.view)
+	}
+}
+
+func TestModel_ClustersLoaded_NoClusters(t *testing.T) {
+	client := azure.NewMockClient()
+	client.ListAKSClustersFunc = func(_ context.Context, _ string) ([]azure.AKSCluster, error) {
+		return nil, nil
+	}
+	model := NewModel(client, WithKubernetes(config.Kubernetes{Enabled: true}))
+	model.state = StateReady
+	model.allSubscriptions = []azure.Subscription{{Name: "Production", ID: "sub-id"}}
+
+	newModel, _ := model.Update(model.loadClusters("sub-id")())
+	m := newModel.(Model)
+
+	if m.view != ViewSubscriptions {
+		t.Errorf("expected to stay in the subscription view, got %v", m.view)
+	}
+	if m.message != "No AKS clusters in Production" {
+		t.Errorf("expected a message about no clusters, got %q", m.message)
+	}
+}
+
+func TestModel_ClustersLoaded_QuitAfterSwitch(t *testing.T) {
+	client := azure.NewMockClient()
+	model := NewModel(client, WithKubernetes(config.Kubernetes{Enabled: true}))
+	model.state = StateReady
+	model.quitAfterSwitch = true
+	model.allSubscriptions = []azure.Subscription{{Name: "Production", ID: "sub-id"}}
+
+	newModel, _ := model.Update(model.loadClusters("sub-id")())
+	m := newModel.(Model)
+	if !strings.Contains(m.View(), "Pick a kubectl context for Production") {
+		t.Errorf("expected the subscription name from all subscriptions, got:\n%s", m.View())
+	}
+
+	// Esc leaves the cluster picker and, after a switch, azswitch.
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = newModel.(Model); !m.quitting || cmd == nil {
+		t.Error("expected esc to quit")
+	}
+
+	// As does a subscription without clusters.
+	client.ListAKSClustersFunc = func(_ context.Context, _ string) ([]azure.AKSCluster, error) {
+		return nil, nil
+	}
+	newModel, cmd = model.Update(model.loadClusters("sub-id")())
+	if m = newModel.(Model); !m.quitting || cmd == nil {
+		t.Error("expected no clusters to quit")
+	}
+}
+
+func TestModel_UseCluster_ExistingContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	kc := `apiVersion: v1
+kind: Config
+contexts:
+  - name: test-aks
+    context:
+      cluster: test-aks
+      user: test-user
+  - name: other
+    context:
+      cluster: other
+      user: other-user
+current-context: other
+`
+	if err := os.WriteFile(path, []byte(kc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := azure.NewMockClient()
+	model := NewModel(client, WithKubernetes(config.Kubernetes{Enabled: true, Kubeconfig: path}))
+
+	msg := model.useCluster(azure.AKSCluster{Name: "test-aks", ResourceGroup: "test-rg"})()
+
+	set, ok := msg.(kubeContextSetMsg)
+	if !ok {
+		t.Fatalf("expected kubeContextSetMsg, got %T: %v", msg, msg)
+	}
+
+	if set.context != "test-aks" {
+		t.Errorf("expected context 'test-aks', got '%s'", set.context)
+	}
+
+	if len(client.Calls.GetAKSCredentials) != 0 {
+		t.Error("expected existing context to be reused without get-credentials")
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "current-context: test-aks") {
+		t.Errorf("expected kubeconfig current-context to be updated, got:\n%s", data)
+	}
+}
+
+func TestModel_UseCluster_FetchesCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	client := azure.NewMockClient()
+	model := NewModel(client, WithKubernetes(config.Kubernetes{Enabled: true, Kubeconfig: path}))
+
+	msg := model.useCluster(azure.AKSCluster{Name: "new-aks", ResourceGroup: "rg"})()
+
+	if _, ok := msg.(kubeContextSetMsg); !ok {
+		t.Fatalf("expected kubeContextSetMsg, got %T: %v", msg, msg)
+	}
+
+	if len(client.Calls.GetAKSCredentials) != 1 || client.Calls.GetAKSCredentials[0] != "new-aks" {
+		t.Errorf("expected get-credentials for new-aks, got %v", client.Calls.GetAKSCredentials)
+	}
+}
+
+func TestModel_ReadOnly(t *testing.T) {
+	client := azure.NewMockClient()
+	model := NewModel(client, WithReadOnly())
+	model.state = StateReady
+	model.account = &azure.Account{Name: "Sub 1", TenantID: "tid-1"}
+	model.subscriptions = []azure.Subscription{
+		{Name: "Sub 1", ID: "id-1", IsDefault: true},
+		{Name: "Sub 2", ID: "id-2"},
+	}
+	model.tenants = []azure.Tenant{{DisplayName: "Tenant 2", TenantID: "tid-2"}}
+	model.cursor = 1
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(Model)
+
+	if cmd != nil || m.state != StateReady {
+		t.Error("expected enter to do nothing in read-only mode")
+	}
+
+	m.view = ViewDirectories
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if cmd != nil || m.state != StateReady {
+		t.Error("expected tenant login not to start in read-only mode")
+	}
+
+	// Even a direct switch attempt is rejected by the wrapped client.
+	msg := m.switchSubscription(m.subscriptions[1])()
+	if em, ok := msg.(errMsg); !ok || !errors.Is(em.err, azure.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", msg)
+	}
+
+	if len(client.Calls.SetSubscription) != 0 {
+		t.Error("expected SetSubscription not to reach the client")
+	}
+
+	if !strings.Contains(m.View(), "READ-ONLY") {
+		t.Error("expected read-only badge in view")
+	}
+}
+
+func TestModel_WithConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.DefaultView = config.ViewDirectories
+	cfg.Keys = map[string][]string{"down": {"ctrl+n"}}
+	cfg.Aliases = map[string]string{"s2": "id-2"}
+	cfg.Favorites = []string{"Sub 1"}
+
+	model := NewModel(azure.NewMockClient(), WithConfig(cfg))
+
+	if model.view != ViewDirectories {
+		t.Errorf("expected view to be ViewDirectories, got %v", model.view)
+	}
+
+	model.state = StateReady
+	model.view = ViewSubscriptions
+	model.subscriptions = []azure.Subscription{
+		{Name: "Sub 1", ID: "id-1"},
+		{Name: "Sub 2", ID: "id-2"},
+	}
+
+	// j is no longer bound to down.
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m := newModel.(Model)
+	if m.cursor != 0 {
+		t.Errorf("expected j to be unbound, cursor moved to %d", m.cursor)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	m = newModel.(Model)
+	if m.cursor != 1 {
+		t.Errorf("expected ctrl+n to move down, cursor is %d", m.cursor)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "★") || !strings.Contains(view, "(s2)") {
+		t.Errorf("expected favorite star and alias in view:\n%s", view)
+	}
+}
+
+// readyModel returns a model that has loaded n subscriptions, the first one current.
+func readyModel(t *testing.T, n int, opts ...Option) Model {
+	t.Helper()
+	subs := make([]azure.Subscription, n)
+	for i := range subs {
+		subs[i] = azure.Subscription{Name: fmt.Sprintf("Sub %02d", i+1), ID: fmt.Sprintf("id-%02d", i+1), IsDefault: i == 0}
+	}
+
+	next, _ := NewModel(azure.NewMockClient(), opts...).Update(dataLoadedMsg{
+		account:       &azure.Account{Name: "Sub 01", ID: "id-01"},
+		subscriptions: subs,
+		tenants:       []azure.Tenant{{DisplayName: "Tenant 1", TenantID: "tid-1"}},
+	})
+	return next.(Model)
+}
+
+// cellOf returns the screen position of the first occurrence of text in the view.
+func cellOf(t *testing.T, m Model, text string) (x, y int) {
+	t.Helper()
+	for y, line := range strings.Split(m.View(), "\n") {
+		if x := strings.Index(line, text); x >= 0 {
+			return lipgloss.Width(line[:x]), y
+		}
+	}
+	t.Fatalf("%q not found in view:\n%s", text, m.View())
+	return 0, 0
+}
+
+func click(m Model, x, y int) (Model, tea.Cmd) {
+	next, cmd := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	return next.(Model), cmd
+}
+
+func TestModel_Mouse_ClickMovesCursor(t *testing.T) {
+	m := readyModel(t, 3)
+
+	x, y := cellOf(t, m, "Sub 03")
+	m, cmd := click(m, x, y)
+	if m.cursor != 2 {
+		t.Errorf("expected cursor on Sub 03, got %d", m.cursor)
+	}
+	if cmd != nil || m.state != StateReady {
+		t.Error("expected a single click not to switch")
+	}
+
+	// The ID line below the name belongs to the same row.
+	x, y = cellOf(t, m, "id-02")
+	m, _ = click(m, x, y)
+	if m.cursor != 1 {
+		t.Errorf("expected cursor on Sub 02, got %d", m.cursor)
+	}
+}
+
+func TestModel_Mouse_DoubleClickSelects(t *testing.T) {
+	client := azure.NewMockClient()
+	m := readyModel(t, 3)
+	m.client = client
+
+	x, y := cellOf(t, m, "Sub 02")
+	m, _ = click(m, x, y)
+	m, cmd := click(m, x, y)
+	if m.state != StateSwitching || cmd == nil {
+		t.Fatalf("expected a double-click to switch, state %v", m.state)
+	}
+}
+
+func TestModel_Mouse_ClickTabs(t *testing.T) {
+	m := readyModel(t, 3)
+
+	x, y := cellOf(t, m, "Directories")
+	m, _ = click(m, x, y)
+	if m.view != ViewDirectories {
+		t.Fatalf("expected directories view, got %v", m.view)
+	}
+
+	x, y = cellOf(t, m, "Subscriptions")
+	m, _ = click(m, x+len("Subscriptions")-1, y)
+	if m.view != ViewSubscriptions {
+		t.Errorf("expected subscriptions view, got %v", m.view)
+	}
+
+	// The separator between tabs is not a tab.
+	x, y = cellOf(t, m, "|")
+	m, _ = click(m, x, y)
+	if m.view != ViewSubscriptions {
+		t.Errorf("expected a click between tabs to do nothing, got %v", m.view)
+	}
+}
+
+func TestModel_Mouse_WheelScrolls(t *testing.T) {
+	m := readyModel(t, 30)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = next.(Model)
+
+	if !strings.Contains(m.View(), "Sub 01") || strings.Contains(m.View(), "Sub 30") {
+		t.Fatal("expected a long list to be cut to the window height")
+	}
+	if got := strings.Count(m.View(), "\n") + 1; got > 24 {
+		t.Errorf("expected at most 24 lines, got %d", got)
+	}
+
+	for range 5 {
+		next, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+		m = next.(Model)
+	}
+	if strings.Contains(m.View(), "Sub 05") || !strings.Contains(m.View(), "Sub 06") {
+		t.Errorf("expected the list to start at Sub 06, got:\n%s", m.View())
+	}
+	if m.cursor != 5 {
+		t.Errorf("expected the cursor to stay visible on Sub 06, got %d", m.cursor)
+	}
+
+	// Clicks hit-test against the scrolled list.
+	x, y := cellOf(t, m, "Sub 08")
+	m, _ = click(m, x, y)
+	if m.cursor != 7 {
+		t.Errorf("expected cursor on Sub 08, got %d", m.cursor)
+	}
+
+	for range 50 {
+		next, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+		m = next.(Model)
+	}
+	if !strings.Contains(m.View(), "Sub 30") {
+		t.Error("expected scrolling to stop at the end of the list")
+	}
+
+	for range 50 {
+		next, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+		m = next.(Model)
+	}
+	if !strings.Contains(m.View(), "Sub 01") {
+		t.Error("expected scrolling back to the top")
+	}
+}
+
+func TestModel_KeyNavigation_ScrollsToCursor(t *testing.T) {
+	m := readyModel(t, 30)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = next.(Model)
+
+	for range 29 {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = next.(Model)
+	}
+	if !strings.Contains(m.View(), "Sub 30") {
+		t.Error("expected the list to follow the cursor")
+	}
+}
+
+func TestModel_Inline_CompactAndLimited(t *testing.T) {
+	m := readyModel(t, 30, WithInline(8))
+
+	view := m.View()
+	if got := strings.Count(view, "\n") + 1; got > 8 {
+		t.Errorf("expected at most 8 lines, got %d:\n%s", got, view)
+	}
+	if !strings.Contains(view, "Sub 01") || !strings.Contains(view, "id-01") {
+		t.Errorf("expected name and ID on the same line, got:\n%s", view)
+	}
+	if strings.Contains(view, "Sub 30") {
+		t.Error("expected the list to be cut to the inline height")
+	}
+
+	for range 29 {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = next.(Model)
+	}
+	if !strings.Contains(m.View(), "Sub 30") {
+		t.Error("expected the inline list to follow the cursor")
+	}
+}
+
+func TestModel_Inline_SummaryOnExit(t *testing.T) {
+	m := readyModel(t, 3, WithInline(10))
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if got := next.(Model).View(); !strings.Contains(got, "Kept Sub 01 (id-01)") || strings.Count(got, "\n") != 1 {
+		t.Errorf("expected a one-line summary of the unchanged account, got %q", got)
+	}
+
+	next, _ = m.Update(switchedMsg{message: "Subscription switched successfully", subscriptionID: "id-02"})
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if got := next.(Model).View(); !strings.Contains(got, "Switched to Sub 02 (id-02)") {
+		t.Errorf("expected a summary of the switch, got %q", got)
+	}
+}
+
+func TestModel_FullScreen_QuitLeavesNothing(t *testing.T) {
+	m := readyModel(t, 3)
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if got := next.(Model).View(); got != "" {
+		t.Errorf("expected an empty view after quitting, got %q", got)
+	}
+}
+
+func TestModel_Picker_PicksWithoutSwitching(t *testing.T) {
+	client := azure.NewMockClient()
+	m := readyModel(t, 3, WithPicker(false))
+	m.client = client
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	next, cmd := next.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+
+	subs, tenants := m.Picked()
+	if len(subs) != 1 || subs[0].ID != "id-02" || len(tenants) != 0 {
+		t.Fatalf("expected id-02 to be picked, got %v, %v", subs, tenants)
+	}
+	if cmd == nil || !m.quitting {
+		t.Error("expected picking to quit")
+	}
+	if len(client.Calls.SetSubscription) != 0 {
+		t.Error("expected nothing to be switched")
+	}
+	if m.View() != "" {
+		t.Error("expected the picker to leave nothing behind")
+	}
+}
+
+func TestModel_Picker_Multi(t *testing.T) {
+	m := readyModel(t, 4, WithPicker(true))
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	next, _ := m.Update(space) // marks Sub 01, moves to Sub 02
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyDown})
+	next, _ = next.(Model).Update(space) // marks Sub 03
+	if !strings.Contains(next.(Model).View(), "◉") {
+		t.Error("expected marks to be shown")
+	}
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	subs, _ := next.(Model).Picked()
+	if len(subs) != 2 || subs[0].ID != "id-01" || subs[1].ID != "id-03" {
+		t.Errorf("expected the marked subscriptions, got %v", subs)
+	}
+}
+
+func TestModel_Picker_TenantAndCancel(t *testing.T) {
+	m := readyModel(t, 2, WithPicker(false))
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if subs, tenants := next.(Model).Picked(); len(subs) != 0 || len(tenants) != 1 || tenants[0].TenantID != "tid-1" {
+		t.Errorf("expected tid-1 to be picked, got %v, %v", subs, tenants)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if subs, tenants := next.(Model).Picked(); len(subs)+len(tenants) != 0 {
+		t.Error("expected nothing picked after cancelling")
+	}
+}
+
+func TestModel_Tags_BadgesAndFilter(t *testing.T) {
+	client := azure.NewMockClient()
+	client.GetSubscriptionTagsFunc = func(_ context.Context, id string) (map[string]string, error) {
+		if id == "id-01" {
+			return map[string]string{"env": "prod", "owner": "team-a"}, nil
+		}
+		return map[string]string{"env": "dev"}, nil
+	}
+	cfg := config.Default()
+	cfg.Tags.Show = []string{"env"}
+
+	next, _ := NewModel(client, WithConfig(cfg), WithTags(filepath.Join(t.TempDir(), "tags.json"))).Update(dataLoadedMsg{
+		account: &azure.Account{Name: "Sub 01", ID: "id-01"},
+		subscriptions: []azure.Subscription{
+			{Name: "Sub 01", ID: "id-01", IsDefault: true},
+			{Name: "Sub 02", ID: "id-02"},
+		},
+	})
+	m := next.(Model)
+	if strings.Contains(m.View(), "env=") {
+		t.Fatal("expected no badges before the tags are fetched")
+	}
+
+	next, _ = m.Update(m.fetchTags()())
+	m = next.(Model)
+	view := m.View()
+	if !strings.Contains(view, "[env=prod]") || !strings.Contains(view, "[env=dev]") || strings.Contains(view, "owner") {
+		t.Errorf("expected only env badges, got:\n%s", view)
+	}
+
+	m.filterText = "tag.env=dev"
+	m.applyFilter()
+	if len(m.subscriptions) != 1 || m.subscriptions[0].ID != "id-02" {
+		t.Errorf("expected the filter to match Sub 02 by tag, got %v", m.subscriptions)
+	}
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickInterval is the longest gap between the clicks of a double-click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// handleMouseMsg handles clicks on tabs and list items, and wheel scrolling.
+func (m Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Don't handle the mouse while loading or switching
+	if m.state == StateLoading || m.state == StateSwitching {
+		return m, nil
+	}
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scroll(-1)
+	case tea.MouseButtonWheelDown:
+		m.scroll(1)
+	case tea.MouseButtonLeft:
+		return m.click(msg.X, msg.Y)
+	}
+	return m, nil
+}
+
+// click handles a left click at the given cell: a tab switches views, an
+// item moves the cursor, and a second click on the same item selects it.
+func (m Model) click(x, y int) (tea.Model, tea.Cmd) {
+	f := m.frame()
+
+	if y == f.tabsLine {
+		for _, tab := range f.tabs {
+			if x >= tab.start && x < tab.end && tab.view != m.view {
+				m.setView(tab.view)
+				m.lastClick = time.Time{}
+			}
+		}
+		return m, nil
+	}
+
+	item, ok := f.itemAt(y, m.offset, m.cursorPosition())
+	if !ok {
+		return m, nil
+	}
+
+	cursor, _ := m.currentCursor()
+	*cursor = item
+
+	now := m.now()
+	if item == m.lastClickItem && m.view == m.lastClickView && now.Sub(m.lastClick) <= doubleClickInterval {
+		m.lastClick = time.Time{}
+		return m.handleSelect()
+	}
+	m.lastClick, m.lastClickItem, m.lastClickView = now, item, m.view
+	return m, nil
+}
+
+// scroll moves the list window by delta items, keeping the cursor within it.
+func (m *Model) scroll(delta int) {
+	cursor, _ := m.currentCursor()
+	f := m.frame()
+	if cursor == nil || f.height == 0 || len(f.items) == 0 {
+		return
+	}
+
+	m.offset = min(max(m.offset+delta, 0), f.lastStart())
+	*cursor = min(max(*cursor, m.offset), f.fill(m.offset)-1)
+}
+
+// itemAt returns the item rendered on screen line y.
+func (f frame) itemAt(y, offset, cursor int) (int, bool) {
+	line := strings.Count(f.top, "\n")
+	start, end := f.window(offset, cursor)
+	for i := start; i < end; i++ {
+		h := strings.Count(f.items[i], "\n")
+		if y >= line && y < line+h {
+			return i, true
+		}
+		line += h
+	}
+	return 0, false
+}
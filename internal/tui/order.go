@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/listing"
+)
+
+// sortLabels describes the sort orders in status messages.
+var sortLabels = map[string]string{
+	config.SortName:   "name",
+	config.SortTenant: "tenant",
+	config.SortState:  "state",
+	config.SortRecent: "recently used",
+	config.SortUsage:  "most used",
+}
+
+// listRow is a line of the subscriptions list: a group header or a subscription.
+type listRow struct {
+	// group is set on a group header.
+	group *listing.Group
+
+	// collapsed is set on the header of a collapsed group.
+	collapsed bool
+
+	// sub is set on a subscription.
+	sub *azure.Subscription
+}
+
+// key identifies the row across refreshes.
+func (r listRow) key() string {
+	if r.group != nil {
+		return "group:" + r.group.Key
+	}
+	return r.sub.ID
+}
+
+// rows returns the lines of the subscriptions list: the subscriptions in
+// order, under a header per group when grouped. Collapsed groups show only
+// their header.
+func (m Model) rows() []listRow {
+	groups := listing.Groups(m.subscriptions, m.grouping)
+
+	var rows []listRow
+	for i := range groups {
+		g := &groups[i]
+		collapsed := false
+		if g.Title != "" {
+			collapsed = m.collapsed[m.groupKey(g)]
+			rows = append(rows, listRow{group: g, collapsed: collapsed})
+		}
+		if collapsed {
+			continue
+		}
+		for j := range g.Subscriptions {
+			rows = append(rows, listRow{sub: &g.Subscriptions[j]})
+		}
+	}
+	return rows
+}
+
+// groupKey identifies a group in m.collapsed. It includes the grouping, so
+// that a tenant and a cloud never share a key.
+func (m Model) groupKey(g *listing.Group) string {
+	return m.grouping + ":" + g.Key
+}
+
+// selectedRow returns the row under the cursor of the subscriptions list.
+func (m Model) selectedRow() (listRow, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return listRow{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// selectedSubscription returns the subscription under the cursor, or nil on
+// a group header or an empty list.
+func (m Model) selectedSubscription() *azure.Subscription {
+	row, _ := m.selectedRow()
+	return row.sub
+}
+
+// selectedKey returns the key of the row under the cursor, or "" if there is none.
+func (m Model) selectedKey() string {
+	if row, ok := m.selectedRow(); ok {
+		return row.key()
+	}
+	return ""
+}
+
+// moveCursorTo puts the cursor on the row with the given key, or on the
+// first row if there is none.
+func (m *Model) moveCursorTo(key string) {
+	m.cursor = 0
+	for i, row := range m.rows() {
+		if row.key() == key {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// toggleGroup collapses or expands a group, keeping the cursor on its header.
+func (m *Model) toggleGroup(g *listing.Group) {
+	key := m.groupKey(g)
+	m.setCollapsed(key, !m.collapsed[key])
+	m.moveCursorTo("group:" + g.Key)
+}
+
+// cycleSort switches the subscriptions list to the next sort order and saves it.
+func (m Model) cycleSort() (tea.Model, tea.Cmd) {
+	m.sortOrder = next(config.Sorts, m.sortOrder)
+	m.reorder()
+	m.clearStatus()
+	m.message = "Sorted by " + sortLabels[m.sortOrder]
+	return m, m.saveList()
+}
+
+// cycleGroup switches the subscriptions list to the next grouping and saves it.
+func (m Model) cycleGroup() (tea.Model, tea.Cmd) {
+	key := m.selectedKey()
+	m.grouping = next(config.Groups, m.grouping)
+	m.moveCursorTo(key)
+	m.clearStatus()
+	m.message = "Grouped by " + m.grouping
+	if m.grouping == config.GroupNone {
+		m.message = "Not grouped"
+	}
+	return m, m.saveList()
+}
+
+// reorder applies the sort order to the subscriptions list, keeping the
+// cursor on the same subscription.
+func (m *Model) reorder() {
+	key := m.selectedKey()
+	m.subscriptions = listing.Sort(m.subscriptions, m.sortOrder, m.usage)
+	m.moveCursorTo(key)
+}
+
+// saveList saves the sort order and grouping to the config file, if there is one.
+func (m Model) saveList() tea.Cmd {
+	if m.configPath == "" {
+		return nil
+	}
+
+	path, list, message := m.configPath, config.List{Sort: m.sortOrder, Group: m.grouping}, m.message
+	return func() tea.Msg {
+		if err := config.SetList(path, list); err != nil {
+			return actionDoneMsg{message: message, err: fmt.Errorf("list order not saved: %w", err)}
+		}
+		return actionDoneMsg{message: message}
+	}
+}
+
+// renderGroupHeader renders the header of a group in the subscriptions list.
+func renderGroupHeader(row listRow, selected bool) string {
+	arrow := "▾"
+	if row.collapsed {
+		arrow = "▸"
+	}
+
+	title := TitleStyle.UnsetMarginBottom().Render(row.group.Title)
+	if selected {
+		title = SelectedStyle.Render(row.group.Title)
+	}
+	return fmt.Sprintf("%s %s %s", MutedStyle.Render(arrow), title, MutedStyle.Render(fmt.Sprintf("(%d)", len(row.group.Subscriptions))))
+}
+
+// next returns the value after current in values, wrapping around.
+func next(values []string, current string) string {
+	i := slices.Index(values, current)
+	return values[(i+1)%len(values)]
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+	"github.com/l2D/azswitch/internal/history"
+)
+
+// groupedModel returns a ready model with subscriptions in two tenants, in
+// the order az might return them.
+func groupedModel(t *testing.T, usage *history.History, opts ...Option) Model {
+	t.Helper()
+	next, _ := NewModel(azure.NewMockClient(), opts...).Update(dataLoadedMsg{
+		account: &azure.Account{Name: "Web", ID: "id-web"},
+		subscriptions: []azure.Subscription{
+			{Name: "Web", ID: "id-web", TenantID: "t2", TenantDisplayName: "Zeta", IsDefault: true},
+			{Name: "Data", ID: "id-data", TenantID: "t1", TenantDisplayName: "Acme"},
+			{Name: "Apps", ID: "id-apps", TenantID: "t2", TenantDisplayName: "Zeta"},
+		},
+		usage: usage,
+	})
+	return next.(Model)
+}
+
+func rowNames(m Model) string {
+	var names []string
+	for _, row := range m.rows() {
+		if row.group != nil {
+			names = append(names, "["+row.group.Title+"]")
+		} else {
+			names = append(names, row.sub.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func TestModel_Sort_CyclesAndKeepsCursor(t *testing.T) {
+	var usage history.History
+	usage.Add("id-data", time.Now())
+	usage.Add("id-apps", time.Now().Add(-time.Hour))
+	usage.Add("id-apps", time.Now().Add(-time.Hour))
+
+	m := groupedModel(t, &usage)
+	if got := rowNames(m); got != "Apps,Data,Web" {
+		t.Fatalf("expected name order by default, got %s", got)
+	}
+	if sub := m.selectedSubscription(); sub == nil || sub.ID != "id-web" {
+		t.Fatalf("expected the cursor on the default subscription, got %v", sub)
+	}
+
+	m, _ = press(m, runes("s"), runes("s"), runes("s"))
+	if m.sortOrder != config.SortRecent || rowNames(m) != "Data,Apps,Web" {
+		t.Errorf("expected recent order, got %s by %s", rowNames(m), m.sortOrder)
+	}
+	if !strings.Contains(m.View(), "sorted by recently used") {
+		t.Errorf("expected the order to be shown, got:\n%s", m.View())
+	}
+
+	m, _ = press(m, runes("s"))
+	if rowNames(m) != "Apps,Data,Web" || m.message != "Sorted by most used" {
+		t.Errorf("expected usage order, got %s (%q)", rowNames(m), m.message)
+	}
+	if sub := m.selectedSubscription(); sub == nil || sub.ID != "id-web" {
+		t.Errorf("expected the cursor to stay on Web, got %v", sub)
+	}
+}
+
+func TestModel_Group_CollapsesInReadOnly(t *testing.T) {
+	m := groupedModel(t, nil, WithReadOnly())
+
+	m, _ = press(m, runes("g"))
+	if got := rowNames(m); got != "[Acme],Data,[Zeta],Apps,Web" {
+		t.Fatalf("expected groups per tenant, got %s", got)
+	}
+
+	m, _ = press(m, upMsg, upMsg, enterMsg)
+	if got := rowNames(m); got != "[Acme],Data,[Zeta]" {
+		t.Fatalf("expected Zeta to collapse, got %s", got)
+	}
+	if row, _ := m.selectedRow(); row.group == nil || row.group.Title != "Zeta" {
+		t.Errorf("expected the cursor to stay on the header, got %+v", row)
+	}
+	if !strings.Contains(m.View(), "▸") {
+		t.Errorf("expected a collapsed marker, got:\n%s", m.View())
+	}
+
+	m, _ = press(m, enterMsg)
+	if got := rowNames(m); got != "[Acme],Data,[Zeta],Apps,Web" {
+		t.Errorf("expected Zeta to expand again, got %s", got)
+	}
+
+	m, _ = press(m, runes("g"), runes("g"), runes("g"))
+	if got := rowNames(m); got != "Apps,Data,Web" || m.message != "Not grouped" {
+		t.Errorf("expected no grouping, got %s (%q)", got, m.message)
+	}
+}
+
+func TestModel_Sort_SavedToConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	m := groupedModel(t, nil, WithConfigFile(path))
+
+	m, cmd := press(m, runes("g"))
+	if cmd == nil {
+		t.Fatal("expected the order to be saved")
+	}
+	next, _ := m.Update(cmd())
+	if next.(Model).warning != nil {
+		t.Fatalf("unexpected warning: %v", next.(Model).warning)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.List.Sort != config.SortName || cfg.List.Group != config.GroupTenant {
+		t.Errorf("expected name/tenant to be saved, got %+v", cfg.List)
+	}
+}
+
+func TestModel_Switch_RecordsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	m := groupedModel(t, nil, WithHistory(path))
+
+	m, cmd := press(m, upMsg, enterMsg)
+	if cmd == nil {
+		t.Fatal("expected a switch")
+	}
+	if _, ok := m.switchSubscription(*m.selectedSubscription())().(switchedMsg); !ok {
+		t.Fatal("expected the switch to succeed")
+	}
+
+	h, err := history.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Get("id-data").Count != 1 {
+		t.Errorf("expected the switch to Data to be recorded, got %+v", h.Subscriptions)
+	}
+}
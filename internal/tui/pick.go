@@ -0,0 +1,72 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// Picked returns the subscriptions or tenants chosen in picker mode. Both
+// are empty when the picker was cancelled.
+func (m Model) Picked() ([]azure.Subscription, []azure.Tenant) {
+	return m.pickedSubs, m.pickedTenants
+}
+
+// pick records the marked items of the current view, including those hidden
+// by the filter, or the item under the cursor if none are marked, and quits.
+// A subscription under the cursor in the directories tree is picked as such.
+func (m Model) pick() (tea.Model, tea.Cmd) {
+	switch m.view {
+	case ViewSubscriptions:
+		m.pickedSubs = m.markedSubscriptions()
+		if sub := m.selectedSubscription(); len(m.pickedSubs) == 0 && sub != nil {
+			m.pickedSubs = append(m.pickedSubs, *sub)
+		}
+	case ViewDirectories:
+		if row, ok := m.selectedDirRow(); ok && row.sub != nil {
+			m.pickedSubs = []azure.Subscription{*row.sub}
+			break
+		}
+		for i := range m.tenants {
+			if m.marks[m.tenants[i].TenantID] {
+				m.pickedTenants = append(m.pickedTenants, m.tenants[i])
+			}
+		}
+		if row, ok := m.selectedDirRow(); len(m.pickedTenants) == 0 && ok {
+			m.pickedTenants = append(m.pickedTenants, *row.tenant)
+		}
+	default:
+		return m, nil
+	}
+
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// currentID returns the ID of the subscription or tenant under the cursor,
+// or "" on a group header or a subscription in the directories tree.
+func (m Model) currentID() string {
+	switch {
+	case m.view == ViewSubscriptions:
+		if sub := m.selectedSubscription(); sub != nil {
+			return sub.ID
+		}
+	case m.view == ViewDirectories:
+		if row, ok := m.selectedDirRow(); ok && row.sub == nil {
+			return row.tenant.TenantID
+		}
+	}
+	return ""
+}
+
+// markColumn renders the mark of an item when marking is enabled.
+func (m Model) markColumn(id string) string {
+	switch {
+	case !m.marking:
+		return ""
+	case m.marks[id]:
+		return SelectedStyle.Render("◉ ")
+	default:
+		return MutedStyle.Render("○ ")
+	}
+}
@@ -1,102 +1,315 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
 
-// Color palette.
-var (
-	primaryColor   = lipgloss.Color("39")  // Azure blue
-	secondaryColor = lipgloss.Color("208") // Orange
-	successColor   = lipgloss.Color("82")  // Green
-	errorColor     = lipgloss.Color("196") // Red
-	mutedColor     = lipgloss.Color("241") // Gray
-	highlightColor = lipgloss.Color("212") // Pink
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/l2D/azswitch/internal/config"
+)
+
+// palette holds the colors of a theme. Adaptive colors pick their light or
+// dark variant from the terminal background.
+type palette struct {
+	primary   lipgloss.TerminalColor
+	secondary lipgloss.TerminalColor
+	success   lipgloss.TerminalColor
+	error     lipgloss.TerminalColor
+	muted     lipgloss.TerminalColor
+	highlight lipgloss.TerminalColor
+	text      lipgloss.TerminalColor
+	badge     lipgloss.TerminalColor
+	statusBg  lipgloss.TerminalColor
+
+	// mono marks a palette without colors, which relies on text attributes.
+	mono bool
+}
+
+// background is the terminal background a theme assumes.
+type background int
+
+const (
+	backgroundAuto background = iota
+	backgroundDark
+	backgroundLight
 )
 
-// Styles for the TUI.
+// theme is a named palette and the background it is designed for.
+type theme struct {
+	palette    palette
+	background background
+}
+
+// standardPalette is the default palette: 256-color values tuned for dark
+// and light backgrounds.
+var standardPalette = palette{
+	primary:   lipgloss.AdaptiveColor{Dark: "39", Light: "25"},   // Azure blue
+	secondary: lipgloss.AdaptiveColor{Dark: "208", Light: "166"}, // Orange
+	success:   lipgloss.AdaptiveColor{Dark: "82", Light: "28"},   // Green
+	error:     lipgloss.AdaptiveColor{Dark: "196", Light: "160"}, // Red
+	muted:     lipgloss.AdaptiveColor{Dark: "241", Light: "244"}, // Gray
+	highlight: lipgloss.AdaptiveColor{Dark: "212", Light: "162"}, // Pink
+	text:      lipgloss.AdaptiveColor{Dark: "252", Light: "235"},
+	badge:     lipgloss.AdaptiveColor{Dark: "0", Light: "15"},
+	statusBg:  lipgloss.AdaptiveColor{Dark: "235", Light: "254"},
+}
+
+// highContrastPalette uses the brightest and darkest colors available.
+var highContrastPalette = palette{
+	primary:   lipgloss.AdaptiveColor{Dark: "51", Light: "19"},
+	secondary: lipgloss.AdaptiveColor{Dark: "226", Light: "130"},
+	success:   lipgloss.AdaptiveColor{Dark: "46", Light: "22"},
+	error:     lipgloss.AdaptiveColor{Dark: "196", Light: "124"},
+	muted:     lipgloss.AdaptiveColor{Dark: "15", Light: "0"},
+	highlight: lipgloss.AdaptiveColor{Dark: "201", Light: "90"},
+	text:      lipgloss.AdaptiveColor{Dark: "15", Light: "0"},
+	badge:     lipgloss.AdaptiveColor{Dark: "0", Light: "15"},
+	statusBg:  lipgloss.AdaptiveColor{Dark: "0", Light: "15"},
+}
+
+// monochromePalette has no colors at all.
+var monochromePalette = palette{
+	primary:   lipgloss.NoColor{},
+	secondary: lipgloss.NoColor{},
+	success:   lipgloss.NoColor{},
+	error:     lipgloss.NoColor{},
+	muted:     lipgloss.NoColor{},
+	highlight: lipgloss.NoColor{},
+	text:      lipgloss.NoColor{},
+	badge:     lipgloss.NoColor{},
+	statusBg:  lipgloss.NoColor{},
+	mono:      true,
+}
+
+// themes maps the names in config.Themes to their definitions.
+var themes = map[string]theme{
+	"auto":          {palette: standardPalette, background: backgroundAuto},
+	"dark":          {palette: standardPalette, background: backgroundDark},
+	"light":         {palette: standardPalette, background: backgroundLight},
+	"high-contrast": {palette: highContrastPalette, background: backgroundAuto},
+	"monochrome":    {palette: monochromePalette, background: backgroundAuto},
+}
+
+// current is the palette the styles were built from.
+var current palette
+
+// Styles for the TUI. They are rebuilt by ApplyTheme.
 var (
 	// Title style for headers.
-	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			MarginBottom(1)
+	TitleStyle lipgloss.Style
 
 	// Subtitle style.
-	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			MarginBottom(1)
+	SubtitleStyle lipgloss.Style
 
 	// Selected item style.
-	SelectedStyle = lipgloss.NewStyle().
-			Foreground(highlightColor).
-			Bold(true)
+	SelectedStyle lipgloss.Style
 
 	// Current item indicator style.
-	CurrentStyle = lipgloss.NewStyle().
-			Foreground(successColor).
-			Bold(true)
+	CurrentStyle lipgloss.Style
 
 	// Normal item style.
-	NormalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+	NormalStyle lipgloss.Style
 
 	// Muted style for secondary text.
-	MutedStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+	MutedStyle lipgloss.Style
 
 	// Error style.
-	ErrorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true)
+	ErrorStyle lipgloss.Style
 
 	// Warning style.
-	WarningStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			Italic(true)
+	WarningStyle lipgloss.Style
 
 	// Success style.
-	SuccessStyle = lipgloss.NewStyle().
-			Foreground(successColor)
+	SuccessStyle lipgloss.Style
 
 	// Help style.
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			MarginTop(1)
+	HelpStyle lipgloss.Style
 
 	// Box style for sections.
-	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(0, 1)
+	BoxStyle lipgloss.Style
 
 	// Header box style.
-	HeaderBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(0, 1).
-			MarginBottom(1)
+	HeaderBoxStyle lipgloss.Style
 
 	// Cursor style.
-	CursorStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			Bold(true)
+	CursorStyle lipgloss.Style
 
 	// Tab style.
-	ActiveTabStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			Underline(true)
+	ActiveTabStyle lipgloss.Style
 
 	// Inactive tab style.
-	InactiveTabStyle = lipgloss.NewStyle().
-				Foreground(mutedColor)
+	InactiveTabStyle lipgloss.Style
 
 	// Spinner style.
-	SpinnerStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor)
+	SpinnerStyle lipgloss.Style
+
+	// Favorite marker style.
+	FavoriteStyle lipgloss.Style
+
+	// Read-only badge style.
+	ReadOnlyBadgeStyle lipgloss.Style
+
+	// Subscription tag badge style.
+	TagStyle lipgloss.Style
 
 	// Status bar style.
-	StatusBarStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("235")).
-			Foreground(lipgloss.Color("252")).
-			Padding(0, 1)
+	StatusBarStyle lipgloss.Style
 )
+
+func init() {
+	lipgloss.SetHasDarkBackground(true)
+	buildStyles(standardPalette)
+}
+
+// ApplyTheme rebuilds the styles from the named theme, one of config.Themes.
+// Themes that adapt to the terminal background detect it immediately, before
+// a TUI takes over the terminal's input.
+func ApplyTheme(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q, must be one of %s", name, strings.Join(config.Themes, ", "))
+	}
+
+	switch t.background {
+	case backgroundDark:
+		lipgloss.SetHasDarkBackground(true)
+	case backgroundLight:
+		lipgloss.SetHasDarkBackground(false)
+	case backgroundAuto:
+		if lipgloss.ColorProfile() != termenv.Ascii {
+			lipgloss.SetHasDarkBackground(lipgloss.DefaultRenderer().Output().HasDarkBackground())
+		}
+	}
+
+	buildStyles(t.palette)
+	return nil
+}
+
+// DisableColor turns off all colors and text attributes, in the TUI and in
+// CLI output alike.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// NoColorRequested reports whether the NO_COLOR environment variable is set
+// (see https://no-color.org).
+func NoColorRequested() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// AccentColor returns the configured accent color of the subscription, if
+// any. Accents are keyed by subscription ID or name; monochrome themes have none.
+func AccentColor(accents map[string]string, id, name string) (lipgloss.TerminalColor, bool) {
+	if current.mono {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(accents))
+	for k := range accents {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		if strings.EqualFold(k, id) || strings.EqualFold(k, name) {
+			if color, ok := config.ResolveColor(accents[k]); ok {
+				return lipgloss.Color(color), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// buildStyles assigns every style from the palette.
+func buildStyles(p palette) {
+	current = p
+
+	TitleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(p.primary).
+		MarginBottom(1)
+
+	SubtitleStyle = lipgloss.NewStyle().
+		Foreground(p.muted).
+		MarginBottom(1)
+
+	SelectedStyle = lipgloss.NewStyle().
+		Foreground(p.highlight).
+		Bold(true).
+		Underline(p.mono)
+
+	CurrentStyle = lipgloss.NewStyle().
+		Foreground(p.success).
+		Bold(true)
+
+	NormalStyle = lipgloss.NewStyle().
+		Foreground(p.text)
+
+	MutedStyle = lipgloss.NewStyle().
+		Foreground(p.muted).
+		Faint(p.mono)
+
+	ErrorStyle = lipgloss.NewStyle().
+		Foreground(p.error).
+		Bold(true)
+
+	WarningStyle = lipgloss.NewStyle().
+		Foreground(p.secondary).
+		Italic(true)
+
+	SuccessStyle = lipgloss.NewStyle().
+		Foreground(p.success)
+
+	HelpStyle = lipgloss.NewStyle().
+		Foreground(p.muted).
+		MarginTop(1)
+
+	BoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(p.primary).
+		Padding(0, 1)
+
+	HeaderBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(p.primary).
+		Padding(0, 1).
+		MarginBottom(1)
+
+	CursorStyle = lipgloss.NewStyle().
+		Foreground(p.secondary).
+		Bold(true)
+
+	ActiveTabStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(p.primary).
+		Underline(true)
+
+	InactiveTabStyle = lipgloss.NewStyle().
+		Foreground(p.muted)
+
+	SpinnerStyle = lipgloss.NewStyle().
+		Foreground(p.secondary)
+
+	FavoriteStyle = lipgloss.NewStyle().
+		Foreground(p.secondary)
+
+	ReadOnlyBadgeStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(p.badge).
+		Background(p.secondary).
+		Reverse(p.mono).
+		Padding(0, 1)
+
+	TagStyle = lipgloss.NewStyle().
+		Foreground(p.primary).
+		Faint(p.mono)
+
+	StatusBarStyle = lipgloss.NewStyle().
+		Background(p.statusBg).
+		Foreground(p.text).
+		Padding(0, 1)
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/config"
+)
+
+// restoreStyles resets the global styles and color profile after a test.
+func restoreStyles(t *testing.T) {
+	t.Helper()
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		_ = ApplyTheme("dark")
+	})
+}
+
+func TestApplyTheme_ConfigThemes(t *testing.T) {
+	restoreStyles(t)
+
+	for _, name := range config.Themes {
+		if err := ApplyTheme(name); err != nil {
+			t.Errorf("theme %q: %v", name, err)
+		}
+	}
+
+	err := ApplyTheme("solarized")
+	if err == nil || !strings.Contains(err.Error(), "high-contrast") {
+		t.Errorf("expected an error listing the themes, got %v", err)
+	}
+}
+
+func TestAccentColor(t *testing.T) {
+	restoreStyles(t)
+	accents := map[string]string{"production": "red", "sub-2": "#00ff00", "Broken": "nope"}
+
+	if c, ok := AccentColor(accents, "sub-1", "Production"); !ok || c != lipgloss.Color("1") {
+		t.Errorf("expected red accent by name, got %v, %v", c, ok)
+	}
+	if c, ok := AccentColor(accents, "sub-2", "Staging"); !ok || c != lipgloss.Color("#00ff00") {
+		t.Errorf("expected green accent by ID, got %v, %v", c, ok)
+	}
+	if _, ok := AccentColor(accents, "sub-3", "Broken"); ok {
+		t.Error("expected an invalid color to be ignored")
+	}
+
+	_ = ApplyTheme("monochrome")
+	if _, ok := AccentColor(accents, "sub-1", "Production"); ok {
+		t.Error("expected no accents in the monochrome theme")
+	}
+}
+
+func TestRenderHeader_Accent(t *testing.T) {
+	restoreStyles(t)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	cfg := config.Default()
+	cfg.Accents = map[string]string{"Production": "#ff0000"}
+	m := NewModel(azure.NewMockClient(), WithConfig(cfg))
+	m.account = &azure.Account{ID: "sub-1", Name: "Production"}
+
+	if !strings.Contains(m.renderHeader(), "38;2;255;0;0") {
+		t.Error("expected the header to use the accent color")
+	}
+
+	DisableColor()
+	if strings.Contains(m.renderHeader(), "\x1b[") {
+		t.Error("expected no escape sequences with colors disabled")
+	}
+}
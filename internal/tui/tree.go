@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/l2D/azswitch/internal/azure"
+	"github.com/l2D/azswitch/internal/listing"
+	"github.com/l2D/azswitch/internal/tokens"
+)
+
+// dirRow is a line of the directories tree: a tenant, or one of its
+// subscriptions while the tenant is expanded.
+type dirRow struct {
+	tenant *azure.Tenant
+
+	// sub is set on a subscription row.
+	sub *azure.Subscription
+
+	// collapsed is set on the row of a collapsed tenant.
+	collapsed bool
+
+	// count is the number of subscriptions of a tenant row.
+	count int
+}
+
+// dirRows returns the lines of the directories tree. Tenants are expanded
+// unless collapsed, and list all their subscriptions in sort order: the
+// filter only applies to the subscriptions list.
+func (m Model) dirRows() []dirRow {
+	all := listing.Sort(m.allSubscriptions, m.sortOrder, m.usage)
+	subsByTenant := make(map[string][]*azure.Subscription)
+	for i := range all {
+		sub := &all[i]
+		subsByTenant[sub.TenantID] = append(subsByTenant[sub.TenantID], sub)
+	}
+
+	rows := make([]dirRow, 0, len(m.tenants)+len(all))
+	for i := range m.tenants {
+		tenant := &m.tenants[i]
+		subs := subsByTenant[tenant.TenantID]
+		collapsed := m.collapsed[treeKey(tenant.TenantID)]
+		rows = append(rows, dirRow{tenant: tenant, collapsed: collapsed, count: len(subs)})
+		if collapsed {
+			continue
+		}
+		for _, sub := range subs {
+			rows = append(rows, dirRow{tenant: tenant, sub: sub})
+		}
+	}
+	return rows
+}
+
+// treeKey identifies a tenant of the directories tree in m.collapsed.
+func treeKey(tenantID string) string {
+	return "directory:" + tenantID
+}
+
+// selectedDirRow returns the row under the cursor of the directories tree.
+func (m Model) selectedDirRow() (dirRow, bool) {
+	rows := m.dirRows()
+	if m.tenantCursor < 0 || m.tenantCursor >= len(rows) {
+		return dirRow{}, false
+	}
+	return rows[m.tenantCursor], true
+}
+
+// setExpanded expands or collapses the tenant or subscription group under
+// the cursor. Collapsing from a member moves the cursor to its header.
+func (m *Model) setExpanded(expanded bool) {
+	switch m.view {
+	case ViewDirectories:
+		row, ok := m.selectedDirRow()
+		if !ok {
+			return
+		}
+		m.setCollapsed(treeKey(row.tenant.TenantID), !expanded)
+		if !expanded {
+			for i, r := range m.dirRows() {
+				if r.sub == nil && r.tenant.TenantID == row.tenant.TenantID {
+					m.tenantCursor = i
+					break
+				}
+			}
+		}
+
+	case ViewSubscriptions:
+		row, ok := m.selectedRow()
+		if !ok {
+			return
+		}
+		group := row.group
+		if group == nil {
+			for _, r := range m.rows() {
+				if r.group != nil {
+					group = r.group
+				}
+				if r.sub == row.sub {
+					break
+				}
+			}
+		}
+		if group == nil {
+			return
+		}
+		m.setCollapsed(m.groupKey(group), !expanded)
+		if !expanded {
+			m.moveCursorTo("group:" + group.Key)
+		}
+	}
+}
+
+// setCollapsed records whether a group or tenant is collapsed. The map is
+// copied so that earlier models are unaffected.
+func (m *Model) setCollapsed(key string, collapsed bool) {
+	c := maps.Clone(m.collapsed)
+	if collapsed {
+		c[key] = true
+	} else {
+		delete(c, key)
+	}
+	m.collapsed = c
+}
+
+// renderDirRow renders a tenant or subscription of the directories tree.
+func (m Model) renderDirRow(row dirRow, selected bool) string {
+	cursor := "  "
+	if selected {
+		cursor = CursorStyle.Render("> ")
+	}
+
+	if row.sub != nil {
+		name := row.sub.Name
+		switch {
+		case row.sub.IsDefault:
+			name = CurrentStyle.Render("• " + name + " ✓")
+		case selected:
+			name = SelectedStyle.Render("• " + name)
+		default:
+			name = MutedStyle.Render("• ") + NormalStyle.Render(name)
+		}
+		return fmt.Sprintf("%s    %s\n", cursor, name)
+	}
+
+	arrow := "▾"
+	if row.collapsed || row.count == 0 {
+		arrow = "▸"
+	}
+
+	name := row.tenant.Title()
+	isCurrent := m.account != nil && row.tenant.TenantID == m.account.TenantID
+	switch {
+	case isCurrent:
+		name = CurrentStyle.Render(name + " ✓")
+	case selected:
+		name = SelectedStyle.Render(name)
+	default:
+		name = NormalStyle.Render(name)
+	}
+
+	var s strings.Builder
+	line := fmt.Sprintf("%s%s%s %s %s", cursor, m.markColumn(row.tenant.TenantID), MutedStyle.Render(arrow), name, MutedStyle.Render(fmt.Sprintf("(%d)", row.count)))
+	if badge := m.tokenBadge(row.tenant.TenantID, m.now()); badge != "" {
+		line += "  " + badge
+	}
+	s.WriteString(line + "\n")
+	if row.count == 0 {
+		s.WriteString(fmt.Sprintf("      %s\n", MutedStyle.Render("(no subscriptions)")))
+	}
+	return s.String()
+}
+
+// tokenBadge renders the token status of a tenant, or "" until it is checked
+// or if the check failed.
+func (m Model) tokenBadge(tenantID string, now time.Time) string {
+	status, ok := m.tokenStatus[tenantID]
+	if !ok {
+		return ""
+	}
+	switch status.State {
+	case tokens.Valid:
+		return SuccessStyle.Render("● " + tokens.Remaining(status.ExpiresOn, now))
+	case tokens.Expiring:
+		return WarningStyle.Render("● expires in " + tokens.Remaining(status.ExpiresOn, now))
+	case tokens.Expired:
+		return ErrorStyle.Render("● login required")
+	}
+	return ""
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/l2D/azswitch/internal/azure"
+)
+
+// treeModel returns a ready model on the directories tab, with two tenants
+// of two subscriptions each, signed in to the first.
+func treeModel(t *testing.T, client *azure.MockClient) Model {
+	t.Helper()
+	next, _ := NewModel(client).Update(dataLoadedMsg{
+		account: &azure.Account{Name: "A1", ID: "a1", TenantID: "ta"},
+		subscriptions: []azure.Subscription{
+			{Name: "A1", ID: "a1", TenantID: "ta", IsDefault: true},
+			{Name: "A2", ID: "a2", TenantID: "ta"},
+			{Name: "B1", ID: "b1", TenantID: "tb"},
+			{Name: "B2", ID: "b2", TenantID: "tb"},
+		},
+		tenants: []azure.Tenant{
+			{DisplayName: "Tenant A", TenantID: "ta"},
+			{DisplayName: "Tenant B", TenantID: "tb"},
+		},
+	})
+	m, _ := press(next.(Model), tea.KeyMsg{Type: tea.KeyTab})
+	return m
+}
+
+func dirRowNames(m Model) string {
+	var names []string
+	for _, row := range m.dirRows() {
+		if row.sub != nil {
+			names = append(names, row.sub.Name)
+		} else {
+			names = append(names, "["+row.tenant.Title()+"]")
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func TestModel_Tree_ExpandAndCollapse(t *testing.T) {
+	m := treeModel(t, azure.NewMockClient())
+	if got := dirRowNames(m); got != "[Tenant A],A1,A2,[Tenant B],B1,B2" {
+		t.Fatalf("expected expanded tenants, got %s", got)
+	}
+
+	left, right := tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyRight}
+	m, _ = press(m, downMsg, downMsg, left)
+	if got := dirRowNames(m); got != "[Tenant A],[Tenant B],B1,B2" {
+		t.Fatalf("expected Tenant A to collapse, got %s", got)
+	}
+	if row, _ := m.selectedDirRow(); row.sub != nil || row.tenant.TenantID != "ta" {
+		t.Errorf("expected the cursor on Tenant A, got %+v", row)
+	}
+
+	m, _ = press(m, right)
+	if got := dirRowNames(m); got != "[Tenant A],A1,A2,[Tenant B],B1,B2" {
+		t.Errorf("expected Tenant A to expand, got %s", got)
+	}
+}
+
+func TestModel_Tree_SwitchesSubscriptionWithoutLogin(t *testing.T) {
+	client := azure.NewMockClient()
+	m := treeModel(t, client)
+
+	m, cmd := press(m, downMsg, downMsg, downMsg, downMsg, enterMsg)
+	row, _ := m.selectedDirRow()
+	if cmd == nil || m.state != StateSwitching || row.sub == nil || row.sub.ID != "b1" {
+		t.Fatalf("expected a switch to B1, got %+v in state %v", row.sub, m.state)
+	}
+
+	msg := m.switchSubscription(*row.sub)()
+	if _, ok := msg.(switchedMsg); !ok {
+		t.Fatalf("expected a direct switch, got %#v", msg)
+	}
+	if strings.Join(client.Calls.GetAccessToken, ",") != "tb" || strings.Join(client.Calls.SetSubscription, ",") != "b1" {
+		t.Errorf("expected a token check for tb and a switch to b1, got %v and %v",
+			client.Calls.GetAccessToken, client.Calls.SetSubscription)
+	}
+
+	// Subscriptions in the current tenant need no token check.
+	m.switchSubscription(m.subscriptions[1])()
+	if len(client.Calls.GetAccessToken) != 1 {
+		t.Errorf("expected no token check in the current tenant, got %v", client.Calls.GetAccessToken)
+	}
+}
+
+func TestModel_Tree_LogsInWhenTokenInvalid(t *testing.T) {
+	client := azure.NewMockClient()
+	client.GetAccessTokenFunc = func(_ context.Context, _ string) (*azure.AccessToken, error) {
+		return nil, fmt.Errorf("%w: %w: AADSTS700082", azure.ErrCommandFailed, azure.ErrAuthRequired)
+	}
+	m := treeModel(t, client)
+
+	msg := m.switchSubscription(m.subscriptions[2])()
+	login, ok := msg.(preHooksDoneMsg)
+	if !ok || login.event.NewTenantID != "tb" || login.event.NewSubscriptionID != "b1" {
+		t.Fatalf("expected a login to tb before switching to b1, got %#v", msg)
+	}
+	if len(client.Calls.SetSubscription) != 0 {
+		t.Error("expected no switch before the login")
+	}
+
+	// After the login, the subscription is switched to.
+	done, ok := m.runPostHooks(login.event, "", "Directory switched successfully")().(switchedMsg)
+	if !ok || done.subscriptionID != "b1" || done.message != "Subscription switched successfully" {
+		t.Errorf("expected a switch to b1 after the login, got %#v", done)
+	}
+	if strings.Join(client.Calls.SetSubscription, ",") != "b1" {
+		t.Errorf("expected b1 to be switched to, got %v", client.Calls.SetSubscription)
+	}
+}
+
+func TestModel_Tree_SwitchesTenantWithoutLogin(t *testing.T) {
+	client := azure.NewMockClient()
+	m := treeModel(t, client)
+
+	m, cmd := press(m, downMsg, downMsg, downMsg, enterMsg)
+	row, _ := m.selectedDirRow()
+	if cmd == nil || row.sub != nil || row.tenant.TenantID != "tb" {
+		t.Fatalf("expected a switch to Tenant B, got %+v", row)
+	}
+
+	done, ok := m.switchTenant("tb")().(switchedMsg)
+	if !ok || done.subscriptionID != "b1" {
+		t.Fatalf("expected a switch to B1 without a login, got %#v", done)
+	}
+	if len(client.Calls.LoginToTenant) != 0 || strings.Join(client.Calls.SetSubscription, ",") != "b1" {
+		t.Errorf("expected only a switch to b1, got %v and %v", client.Calls.LoginToTenant, client.Calls.SetSubscription)
+	}
+}
+
+func TestModel_Tree_LogsInToTenant(t *testing.T) {
+	client := azure.NewMockClient()
+	client.GetAccessTokenFunc = func(_ context.Context, _ string) (*azure.AccessToken, error) {
+		return nil, fmt.Errorf("%w: %w: AADSTS700082", azure.ErrCommandFailed, azure.ErrAuthRequired)
+	}
+	m := treeModel(t, client)
+
+	login, ok := m.switchTenant("tb")().(preHooksDoneMsg)
+	if !ok || login.event.NewTenantID != "tb" || login.event.NewSubscriptionID != "" || login.login.AllowNoSubscriptions {
+		t.Fatalf("expected a login to tb, got %#v", login)
+	}
+
+	// A tenant without subscriptions is logged in to at tenant level.
+	login, ok = m.switchTenant("tc")().(preHooksDoneMsg)
+	if !ok || !login.login.AllowNoSubscriptions {
+		t.Errorf("expected a tenant-level login to tc, got %#v", login)
+	}
+	if len(client.Calls.SetSubscription) != 0 {
+		t.Errorf("expected no switch before the login, got %v", client.Calls.SetSubscription)
+	}
+}
+
+func TestLoginExec_RunsThroughClient(t *testing.T) {
+	client := azure.NewMockClient()
+	var got azure.LoginOptions
+	client.LoginToTenantFunc = func(_ context.Context, _ string, opts azure.LoginOptions) error {
+		got = opts
+		return nil
+	}
+
+	var out bytes.Buffer
+	login := &loginExec{client: client, tenantID: "tb", opts: azure.LoginOptions{UseDeviceCode: true}}
+	login.SetStdin(strings.NewReader(""))
+	login.SetStdout(&out)
+	login.SetStderr(&out)
+	if err := login.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(client.Calls.LoginToTenant, ",") != "tb" || !got.UseDeviceCode || got.Stdin == nil || got.Stdout != &out {
+		t.Errorf("expected a device code login to tb on the terminal, got %v with %+v", client.Calls.LoginToTenant, got)
+	}
+}
+
+func TestModel_Tree_ShowsTokenStatus(t *testing.T) {
+	client := azure.NewMockClient()
+	client.GetAccessTokenFunc = func(_ context.Context, tenantID string) (*azure.AccessToken, error) {
+		if tenantID == "tb" {
+			return nil, fmt.Errorf("%w: %w: AADSTS700082", azure.ErrCommandFailed, azure.ErrAuthRequired)
+		}
+		return &azure.AccessToken{Tenant: tenantID, ExpiresOn: time.Now().Add(time.Hour).Unix()}, nil
+	}
+	m := treeModel(t, client)
+
+	if strings.Contains(m.View(), "login required") {
+		t.Fatal("expected no badges before the check")
+	}
+	next, _ := m.Update(m.checkTokens()())
+	m = next.(Model)
+
+	view := m.View()
+	if !strings.Contains(view, "● 1h00m") && !strings.Contains(view, "● 59m") {
+		t.Errorf("expected Tenant A to show its expiry, got:\n%s", view)
+	}
+	if !strings.Contains(view, "● login required") {
+		t.Errorf("expected Tenant B to need a login, got:\n%s", view)
+	}
+}
+
+func TestModel_Tree_KeepsTokenStatus(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	client := azure.NewMockClient()
+	client.GetAccessTokenFunc = func(_ context.Context, tenantID string) (*azure.AccessToken, error) {
+		if tenantID == "tb" {
+			return nil, fmt.Errorf("%w: %w: AADSTS700082", azure.ErrCommandFailed, azure.ErrAuthRequired)
+		}
+		return &azure.AccessToken{Tenant: tenantID, ExpiresOn: start.Add(time.Hour).Unix()}, nil
+	}
+	m := treeModel(t, client)
+	m.now = func() time.Time { return start }
+
+	next, _ := m.Update(m.checkTokens()())
+	m = next.(Model)
+	checked := len(client.Calls.GetAccessToken)
+	if cmd := m.checkTokens(); cmd != nil {
+		t.Errorf("expected known statuses to be kept, got %#v", cmd())
+	}
+
+	// Switching to a tenant checks it again.
+	next, _ = m.Update(switchedMsg{message: "Directory switched successfully", tenantID: "tb"})
+	m = next.(Model)
+	next, _ = m.Update(m.checkTokens()())
+	m = next.(Model)
+	if got := client.Calls.GetAccessToken[checked:]; len(got) != 1 || got[0] != "tb" {
+		t.Errorf("expected only tb to be checked again, got %v", got)
+	}
+
+	// A token about to expire is checked again.
+	m.now = func() time.Time { return start.Add(55 * time.Minute) }
+	checked = len(client.Calls.GetAccessToken)
+	next, _ = m.Update(m.checkTokens()())
+	m = next.(Model)
+	if got := client.Calls.GetAccessToken[checked:]; len(got) != 1 || got[0] != "ta" {
+		t.Errorf("expected only ta to be checked again, got %v", got)
+	}
+}
+
+func TestModel_Tree_IgnoresFilter(t *testing.T) {
+	m := treeModel(t, azure.NewMockClient())
+	m.filterText = "B1"
+	m.applyFilter()
+
+	if len(m.subscriptions) != 1 {
+		t.Fatalf("expected the filter to match B1 only, got %v", m.subscriptions)
+	}
+	if got := dirRowNames(m); got != "[Tenant A],A1,A2,[Tenant B],B1,B2" {
+		t.Errorf("expected every subscription in the tree, got %s", got)
+	}
+}
+
+func TestModel_Tree_PicksSubscription(t *testing.T) {
+	m := treeModel(t, azure.NewMockClient())
+	m.picker = true
+
+	m, _ = press(m, downMsg, downMsg, enterMsg)
+	subs, tenants := m.Picked()
+	if len(subs) != 1 || subs[0].ID != "a2" || len(tenants) != 0 {
+		t.Errorf("expected A2 to be picked, got %v, %v", subs, tenants)
+	}
+}